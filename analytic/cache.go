@@ -0,0 +1,61 @@
+package analytic
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cache is a least-recently-used cache of the matrices E, F, and G of Fluid
+// (see matrices) keyed by the duration of a time step. The cache is safe for
+// concurrent use.
+type cache struct {
+	sync.Mutex
+
+	capacity uint
+	entries  map[float64]*list.Element
+	order    *list.List
+}
+
+type propagator struct {
+	Δt float64
+	E  []float64
+	F  []float64
+	G  []float64
+}
+
+func newCache(capacity uint) *cache {
+	return &cache{
+		capacity: capacity,
+		entries:  make(map[float64]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (self *cache) get(Δt float64) ([]float64, []float64, []float64, bool) {
+	self.Lock()
+	defer self.Unlock()
+
+	element, ok := self.entries[Δt]
+	if !ok {
+		return nil, nil, nil, false
+	}
+	self.order.MoveToFront(element)
+	propagator := element.Value.(*propagator)
+	return propagator.E, propagator.F, propagator.G, true
+}
+
+func (self *cache) put(Δt float64, E, F, G []float64) {
+	self.Lock()
+	defer self.Unlock()
+
+	if element, ok := self.entries[Δt]; ok {
+		self.order.MoveToFront(element)
+		return
+	}
+	if uint(self.order.Len()) >= self.capacity {
+		element := self.order.Back()
+		self.order.Remove(element)
+		delete(self.entries, element.Value.(*propagator).Δt)
+	}
+	self.entries[Δt] = self.order.PushFront(&propagator{Δt: Δt, E: E, F: F, G: G})
+}
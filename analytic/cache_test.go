@@ -0,0 +1,30 @@
+package analytic
+
+import (
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestCache(t *testing.T) {
+	cache := newCache(2)
+
+	cache.put(1, []float64{1}, []float64{1}, nil)
+	cache.put(2, []float64{2}, []float64{2}, nil)
+
+	E, F, G, ok := cache.get(1)
+	assert.Equal(ok, true, t)
+	assert.Equal(E, []float64{1}, t)
+	assert.Equal(F, []float64{1}, t)
+	assert.Equal(G == nil, true, t)
+
+	cache.put(3, []float64{3}, []float64{3}, nil)
+
+	_, _, _, ok = cache.get(2)
+	assert.Equal(ok, false, t)
+	_, _, _, ok = cache.get(1)
+	assert.Equal(ok, true, t)
+	E, _, _, ok = cache.get(3)
+	assert.Equal(ok, true, t)
+	assert.Equal(E, []float64{3}, t)
+}
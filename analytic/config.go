@@ -1,17 +1,59 @@
 package analytic
 
 import (
-	"github.com/turing-complete/hotspot"
+	"github.com/turing-complete/temperature/circuit"
 )
 
 // Config is a configuration of temperature analysis.
 type Config struct {
-	// The thermal RC model.
-	hotspot.Config
+	// The thermal RC circuit.
+	circuit.Config
 
 	// The ambient temperature.
 	Ambience float64 // in Kelvin
 
+	// The names of the blocks dissipating power, which correspond to the rows
+	// of power profiles. If empty, all the blocks of the floorplan are taken.
+	Sources []string
+
+	// The names of the blocks whose temperature is observed, which correspond
+	// to the rows of temperature profiles. If empty, all the blocks of the
+	// floorplan are taken.
+	Targets []string
+
+	// The flag for observing all thermal nodes instead of Targets (see Nodes
+	// in the integrators).
+	AllNodes bool
+
+	// The temperature above which thermal runaway is reported when static
+	// power is taken into account. If zero, there is no ceiling, and runaway
+	// is still reported when the temperature–leakage loop has no fixed point
+	// (see RunawayError).
+	Ceiling float64 // in Kelvin
+
 	// The sampling interval. The parameter is specific to the Fixed integrator.
 	TimeStep float64 // in seconds
+
+	// The number of distinct durations of time steps whose propagation
+	// matrices are kept for reuse, which saves computations when power
+	// profiles have only a few distinct durations. The least recently used
+	// matrices are discarded first. If zero, no matrices are kept beyond
+	// consecutive samples with the same duration. The parameter is specific
+	// to the Fluid integrator and has no effect in the modal mode (see
+	// Modal).
+	CacheSize uint
+
+	// The flag for propagating the state of the system in the eigenbasis of
+	// the system instead of the basis of the thermal nodes. Then each time
+	// step costs time proportional to the number of thermal nodes instead of
+	// its square, no dense propagation matrix is allocated, and the state of
+	// the system (see State) is expressed in the eigenbasis.
+	Modal bool
+
+	// The flag for interpolating power linearly between consecutive samples
+	// (first-order hold) instead of keeping each sample constant over its
+	// time step (zero-order hold). Then each power sample is the power at the
+	// end of its time step, and the power at the beginning of the first time
+	// step of a power profile is taken to be equal to the first sample.
+	Interpolate bool
 }
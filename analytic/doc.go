@@ -46,4 +46,13 @@
 //     E(t) = exp(A * t) = U * diag(exp(λi * t)) * U**T and
 //     F(t) = A**(-1) * (exp(A * t) - I) * B
 //          = U * diag((exp(λi * t) - 1) / λi) * U**T * B.
+//
+// Assuming that the power dissipation is constant, the steady-state solution
+// to the system is as follows:
+//
+//     S = H * P
+//
+// where
+//
+//     H = -A**(-1) * B = U * diag(-1 / λi) * U**T * B.
 package analytic
@@ -4,21 +4,69 @@ import (
 	"errors"
 	"math"
 
-	"github.com/ready-steady/linear/decomposition"
 	"github.com/ready-steady/linear/matrix"
-	"github.com/turing-complete/hotspot"
+	"github.com/turing-complete/temperature/circuit"
+	"github.com/turing-complete/temperature/internal/check"
+	"github.com/turing-complete/temperature/internal/runaway"
 )
 
 // Fixed is an integrator of a thermal system with a fixed time step.
 type Fixed struct {
 	nc uint
 	nn uint
+	no uint
+
+	Δt float64
+
+	in    [][]circuit.Link
+	out   [][]circuit.Link
+	probe [][]circuit.Link
+
+	modal bool
 
 	D []float64
 	E []float64
 	F []float64
+	H []float64
+
+	// The matrix applied to the previous power sample when power is
+	// interpolated between samples (see Interpolate in Config), in which case
+	// F is applied to the current sample. Otherwise, G is nil.
+	G []float64
+
+	// The eigenvalues and eigenvectors of the system, the projection of the
+	// power onto the eigenbasis (see project), and the matrices mapping the
+	// state of the system in the eigenbasis to the temperature of the targets
+	// and of the sources (see observation). In the modal mode (see Modal in
+	// Config), E is the diagonal of the propagation matrix, and F and H act in
+	// the eigenbasis. Otherwise, Z is nil, and the rest are used only by Peak
+	// and PeriodicSteadyState.
+	Λ []float64
+	U []float64
+	V []float64
+	Y []float64
+	Z []float64
+
+	// The blocks dissipating power, which correspond to the rows of power
+	// profiles.
+	Sources []circuit.Block
+
+	// The blocks whose temperature is observed, which correspond to the rows
+	// of temperature profiles unless all nodes are observed (see Config).
+	Targets []circuit.Block
+
+	// The thermal nodes of the system.
+	Nodes []circuit.Node
+
+	// The steady-state temperature rise of the sources per unit power of the
+	// sources, which is used to detect thermal runaway (see resistance).
+	R []float64
 
 	qamb float64
+	qmax float64
+
+	// The initial temperature of the thermal nodes (see Initial).
+	initial func() ([]float64, error)
 }
 
 // NewFixed returns a new integrator.
@@ -27,27 +75,80 @@ func NewFixed(config *Config) (*Fixed, error) {
 		return nil, errors.New("the time step should be positive")
 	}
 
-	model := hotspot.New((*hotspot.Config)(&config.Config))
-	nc, nn := model.Cores, model.Nodes
+	system, err := decompose(config)
+	if err != nil {
+		return nil, err
+	}
+	nc, nn := system.nc, system.nn
+	Λ, U := system.Λ, system.U
+	V := project(U, system.in, nn)
 
-	A := model.G // Reuse model.G to store A.
-	D := model.C // Reuse model.C to store D.
-	for i := uint(0); i < nn; i++ {
-		D[i] = math.Sqrt(1.0 / model.C[i])
+	Δt := config.TimeStep
+
+	temperature := &Fixed{
+		nc: nc,
+		nn: nn,
+		no: system.no,
+
+		Δt: Δt,
+
+		in:    system.in,
+		out:   system.out,
+		probe: system.probe,
+
+		modal: config.Modal,
+
+		D: system.D,
+
+		Λ: Λ,
+		U: U,
+		V: V,
+		Y: observation(U, system.out, nn),
+
+		Sources: system.sources,
+		Targets: system.targets,
+		Nodes:   system.nodes,
+
+		R: system.R,
+
+		initial: system.initial,
+
+		qamb: config.Ambience,
+		qmax: config.Ceiling,
 	}
-	for i := uint(0); i < nn; i++ {
-		for j := uint(0); j < nn; j++ {
-			A[j*nn+i] = -D[i] * D[j] * A[j*nn+i]
+
+	if config.Modal {
+		E := make([]float64, nn)
+		F := make([]float64, nn*nc)
+		H := make([]float64, nn*nc)
+		var G []float64
+		if config.Interpolate {
+			G = make([]float64, nn*nc)
+		}
+		for i := uint(0); i < nn; i++ {
+			E[i] = math.Exp(Δt * Λ[i])
+			f, h := (E[i]-1.0)/Λ[i], -1.0/Λ[i]
+			g := 0.0
+			if G != nil {
+				g = Δt * phi2(Δt*Λ[i])
+				f, g = g, f-g
+			}
+			for j := uint(0); j < nc; j++ {
+				F[j*nn+i] = f * V[j*nn+i]
+				H[j*nn+i] = h * V[j*nn+i]
+			}
+			if G != nil {
+				for j := uint(0); j < nc; j++ {
+					G[j*nn+i] = g * V[j*nn+i]
+				}
+			}
 		}
-	}
 
-	U := A // Reuse A (which is model.G) to store U.
-	Λ := make([]float64, nn)
-	if err := decomposition.SymmetricEigen(A, U, Λ, nn); err != nil {
-		return nil, err
-	}
+		temperature.E, temperature.F, temperature.G, temperature.H = E, F, G, H
+		temperature.Z = observation(U, system.probe, nn)
 
-	Δt := config.TimeStep
+		return temperature, nil
+	}
 
 	diag := make([]float64, nn)
 	temp := make([]float64, nn*nn)
@@ -61,26 +162,41 @@ func NewFixed(config *Config) (*Fixed, error) {
 	}
 	matrix.Multiply(U, temp, E, nn, nn, nn)
 
-	F := make([]float64, nn*nc)
 	for i := uint(0); i < nn; i++ {
 		diag[i] = (diag[i] - 1.0) / Λ[i]
+	}
+
+	var G []float64
+	if config.Interpolate {
+		G = make([]float64, nn*nc)
+		for i := uint(0); i < nn; i++ {
+			g := Δt * phi2(Δt*Λ[i])
+			for j := uint(0); j < nc; j++ {
+				temp[j*nn+i] = (diag[i] - g) * V[j*nn+i]
+			}
+			diag[i] = g
+		}
+		matrix.Multiply(U, temp, G, nn, nn, nc)
+	}
+
+	F := make([]float64, nn*nc)
+	for i := uint(0); i < nn; i++ {
 		for j := uint(0); j < nc; j++ {
-			temp[j*nn+i] = diag[i] * U[i*nn+j] * D[j]
+			temp[j*nn+i] = diag[i] * V[j*nn+i]
 		}
 	}
 	matrix.Multiply(U, temp, F, nn, nn, nc)
 
-	temperature := &Fixed{
-		nc: nc,
-		nn: nn,
-
-		D: D,
-
-		E: E,
-		F: F,
-
-		qamb: config.Ambience,
+	H := make([]float64, nn*nc)
+	for i := uint(0); i < nn; i++ {
+		diag[i] = -1.0 / Λ[i]
+		for j := uint(0); j < nc; j++ {
+			temp[j*nn+i] = diag[i] * V[j*nn+i]
+		}
 	}
+	matrix.Multiply(U, temp, H, nn, nn, nc)
+
+	temperature.E, temperature.F, temperature.G, temperature.H = E, F, G, H
 
 	return temperature, nil
 }
@@ -88,37 +204,64 @@ func NewFixed(config *Config) (*Fixed, error) {
 // Compute calculates the temperature profile corresponding to a power profile.
 //
 // The power profile is specified by a matrix P containing power samples at a
-// number of equidistant time moments (see TimeStep in Config).
-func (self *Fixed) Compute(P []float64) []float64 {
-	nc, nn := self.nc, self.nn
+// number of equidistant time moments (see TimeStep in Config). The initial
+// state of the system is specified by S0 (see State); if S0 is nil, the system
+// starts at the ambient temperature. The final state of the system is returned
+// along with the temperature profile so that the computation can be resumed
+// by passing the state to a subsequent call.
+//
+// The input is not validated; see ComputeChecked.
+func (self *Fixed) Compute(P []float64, S0 *State) ([]float64, *State) {
+	nc, nn, no := self.nc, self.nn, self.no
 	ns := uint(len(P)) / nc
 
 	S := make([]float64, nn*ns)
-	Q := make([]float64, nc*ns)
-	for i, n, q := uint(0), nc*ns, self.qamb; i < n; i++ {
+	Q := make([]float64, no*ns)
+	for i, n, q := uint(0), no*ns, self.qamb; i < n; i++ {
 		Q[i] = q
 	}
+	if ns == 0 {
+		return Q, S0
+	}
 
-	D, E, F := self.D, self.E, self.F
+	F, G, out, Y := self.F, self.G, self.out, self.Y
 	matrix.Multiply(F, P, S, nn, nc, ns)
+	if G != nil {
+		matrix.MultiplyAdd(G, S0.power(P, nc), S[:nn], S[:nn], nn, nc, 1)
+		if ns > 1 {
+			matrix.MultiplyAdd(G, P[:(ns-1)*nc], S[nn:], S[nn:], nn, nc, ns-1)
+		}
+	}
 	{
 		Si := S[:nn]
-		Qi := Q[:nc]
-		for k := uint(0); k < nc; k++ {
-			Qi[k] += D[k] * Si[k]
+		Qi := Q[:no]
+		if S0 := S0.in(self.basis(), nn); S0 != nil {
+			self.propagate(S0, Si)
 		}
+		observe(self.modal, out, Y, Si, Qi)
 	}
 	for i := uint(1); i < ns; i++ {
 		Sj := S[(i-1)*nn : i*nn]
 		Si := S[i*nn : (i+1)*nn]
-		Qi := Q[i*nc : (i+1)*nc]
-		matrix.MultiplyAdd(E, Sj, Si, Si, nn, nn, 1)
-		for k := uint(0); k < nc; k++ {
-			Qi[k] += D[k] * Si[k]
-		}
+		Qi := Q[i*no : (i+1)*no]
+		self.propagate(Sj, Si)
+		observe(self.modal, out, Y, Si, Qi)
 	}
 
-	return Q
+	return Q, newState(S[(ns-1)*nn:], self.basis(), P[(ns-1)*nc:ns*nc])
+}
+
+// ComputeChecked is the same as Compute except that the input is validated,
+// and an error is returned if it is invalid.
+func (self *Fixed) ComputeChecked(P []float64, S0 *State) ([]float64, *State, error) {
+	if _, err := check.Power(P, self.nc); err != nil {
+		return nil, nil, err
+	}
+	if err := S0.check(self.nc, self.nn); err != nil {
+		return nil, nil, err
+	}
+	Q, S := self.Compute(P, S0)
+	return Q, S, nil
 }
 
 // ComputeWithStatic calculates the temperature profile and the total power
@@ -127,41 +270,266 @@ func (self *Fixed) Compute(P []float64) []float64 {
 //
 // The dynamic power profile is specified by a matrix P containing power samples
 // at a number of equidistant time moments (see TimeStep in Config). The dynamic
-// power profile is overwritten with the total power profile.
-func (self *Fixed) ComputeWithStatic(P []float64, leak func([]float64, []float64)) []float64 {
-	nc, nn := self.nc, self.nn
-	ns := uint(len(P)) / nc
+// power profile is overwritten with the total power profile. The initial state
+// of the system is specified by S0 (see State); if S0 is nil, the system starts
+// at the ambient temperature. The final state of the system is returned along
+// with the temperature profile so that the computation can be resumed by
+// passing the state to a subsequent call.
+//
+// The static power is specified by a function leak(Q, P) that receives the
+// temperature Q of the blocks dissipating power (see Sources) and adds the
+// corresponding static power to the power P of these blocks. Besides once per
+// sample, the function is called with perturbed temperature to detect thermal
+// runaway; hence, it should be free of side effects as it can be called any
+// number of times and with any temperature. If thermal runaway is detected
+// (see Ceiling in Config), a RunawayError is returned.
+func (self *Fixed) ComputeWithStatic(P []float64, S0 *State,
+	leak func([]float64, []float64)) ([]float64, *State, error) {
 
-	S := make([]float64, nn*ns)
-	Q := make([]float64, nc*ns)
-	for i, n, q := uint(0), nc*ns, self.qamb; i < n; i++ {
-		Q[i] = q
+	nc, nn, no := self.nc, self.nn, self.no
+	ns, err := check.Power(P, nc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := S0.check(nc, nn); err != nil {
+		return nil, nil, err
+	}
+	if ns == 0 {
+		return []float64{}, S0, nil
 	}
 
-	D, E, F := self.D, self.E, self.F
-	{
-		Si := S[:nn]
-		Qi := Q[:nc]
-		Pi := P[:nc]
-		leak(Qi, Pi) // Use index 0 as if -1.
-		matrix.Multiply(F, Pi, Si, nn, nc, 1)
-		for k := uint(0); k < nc; k++ {
-			Qi[k] += D[k] * Si[k]
-		}
+	S := make([]float64, nn*ns)
+	Q := make([]float64, no*ns)
+	L := make([]float64, nc)
+
+	detector := runaway.New(self.R, self.qamb, self.qmax, self.Sources)
+
+	F, G, probe, out, Y, Z, qamb := self.F, self.G, self.probe, self.out, self.Y, self.Z, self.qamb
+	for k := uint(0); k < nc; k++ {
+		L[k] = qamb
 	}
-	for i := uint(1); i < ns; i++ {
-		Sj := S[(i-1)*nn : i*nn]
-		Qj := Q[(i-1)*nc : i*nc]
+	S0v := S0.in(self.basis(), nn)
+	if S0v != nil {
+		observe(self.modal, probe, Z, S0v, L)
+	}
+	for i := uint(0); i < ns; i++ {
 		Si := S[i*nn : (i+1)*nn]
-		Qi := Q[i*nc : (i+1)*nc]
+		Qi := Q[i*no : (i+1)*no]
 		Pi := P[i*nc : (i+1)*nc]
-		leak(Qj, Pi)
+
+		Sj := S0v
+		if i > 0 {
+			Sj = S[(i-1)*nn : i*nn]
+		}
+
+		leak(L, Pi)
+		if err := detector.Check(i, L, Pi, leak); err != nil {
+			return nil, nil, err
+		}
+
 		matrix.Multiply(F, Pi, Si, nn, nc, 1)
-		matrix.MultiplyAdd(E, Sj, Si, Si, nn, nn, 1)
+		if G != nil {
+			Pj := S0.power(P, nc)
+			if i > 0 {
+				Pj = P[(i-1)*nc : i*nc]
+			}
+			matrix.MultiplyAdd(G, Pj, Si, Si, nn, nc, 1)
+		}
+		if Sj != nil {
+			self.propagate(Sj, Si)
+		}
+		for k := uint(0); k < no; k++ {
+			Qi[k] = qamb
+		}
+		observe(self.modal, out, Y, Si, Qi)
+
 		for k := uint(0); k < nc; k++ {
-			Qi[k] += D[k] * Si[k]
+			L[k] = qamb
 		}
+		observe(self.modal, probe, Z, Si, L)
+		if err := detector.Check(i, L, nil, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return Q, newState(S[(ns-1)*nn:], self.basis(), P[(ns-1)*nc:ns*nc]), nil
+}
+
+// Peak calculates the peak temperature within each sample of a power profile
+// and the time moments at which the peaks are attained.
+//
+// The power profile is specified by a matrix P containing power samples at a
+// number of equidistant time moments (see TimeStep in Config). The result is a
+// pair of matrices whose i-th columns contain the peak temperature of the
+// targets within the i-th sample, computed with an absolute error of at most
+// ε, and the time moments of the peaks measured from the beginning of the
+// power profile. The initial state of the system is specified by S0 (see
+// State); if S0 is nil, the system starts at the ambient temperature. The
+// final state of the system is returned as well so that the computation can be
+// resumed by passing the state to a subsequent call.
+func (self *Fixed) Peak(P []float64, S0 *State, ε float64) ([]float64, []float64,
+	*State, error) {
+
+	nc, nn, no := self.nc, self.nn, self.no
+	ns, err := check.Power(P, nc)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := S0.check(nc, nn); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := check.Tolerance(ε); err != nil {
+		return nil, nil, nil, err
+	}
+
+	U := self.U
+
+	S := make([]float64, nn)
+	copy(S, S0.in(U, nn))
+
+	ΔT := make([]float64, ns)
+	for i := range ΔT {
+		ΔT[i] = self.Δt
+	}
+
+	var P0 []float64
+	if self.G != nil {
+		P0 = S0.power(P, nc)
+	}
+	Q, T := peaks(self.Λ, self.V, self.Y, P, P0, ΔT, S, nc, nn, no, self.qamb, ε)
+
+	S1 := &State{Power: append([]float64(nil), last(P, nc, S0)...), s: S, u: U}
+
+	return Q, T, S1.to(self.basis(), nn), nil
+}
+
+// State calculates the state of the system corresponding to the temperature of
+// the thermal nodes.
+//
+// The temperature is specified by a vector Q containing one value per thermal
+// node. The result can be used as the initial state in Compute. In the modal
+// mode (see Modal in Config), the state is expressed in the eigenbasis.
+func (self *Fixed) State(Q []float64) (*State, error) {
+	nn := self.nn
+	if err := check.Temperature(Q, nn); err != nil {
+		return nil, err
+	}
+	return temperatureState(Q, self.D, self.basis(), self.qamb, nn), nil
+}
+
+// Initial returns the state of the system corresponding to the initial
+// temperature of the thermal nodes given by the configuration of the thermal
+// RC circuit (see InitialTemperature and InitialFile in circuit.Specs). The
+// result can be used as the initial state in Compute. An error reading the
+// file with the initial temperature is reported here.
+func (self *Fixed) Initial() (*State, error) {
+	Q, err := self.initial()
+	if err != nil {
+		return nil, err
+	}
+	return self.State(Q)
+}
+
+// PeriodicSteadyState calculates the temperature profile corresponding to a
+// power profile that is repeated indefinitely once the temperature has settled
+// into the periodic regime.
+//
+// The power profile is specified by a matrix P containing power samples at a
+// number of equidistant time moments (see TimeStep in Config). The result
+// covers one period of the power profile. When power is interpolated between
+// samples (see Interpolate in Config), the last sample precedes the first one.
+func (self *Fixed) PeriodicSteadyState(P []float64) ([]float64, error) {
+	ns, err := check.Power(P, self.nc)
+	if err != nil {
+		return nil, err
+	}
+	if ns == 0 {
+		return nil, errors.New("the power profile should contain at least one sample")
+	}
+
+	nc, nn, Λ, U := self.nc, self.nn, self.Λ, self.U
+
+	// The power preceding the first sample.
+	Pp := P[(ns-1)*nc:]
+
+	// The state at the end of the period starting from the ambience.
+	_, S0 := self.Compute(P, &State{Power: Pp})
+	S := S0.in(U, nn)
+
+	total := float64(ns) * self.Δt
+
+	// The periodic state solves S0 = exp(total * Λ) * S0 + S in the eigenbasis.
+	for j := uint(0); j < nn; j++ {
+		S[j] /= -math.Expm1(total * Λ[j])
+	}
+
+	Q, _ := self.Compute(P, &State{Power: Pp, s: S, u: U})
+	return Q, nil
+}
+
+// SteadyState calculates the steady-state temperature profile corresponding to
+// a power profile.
+//
+// The power profile is specified by a matrix P containing power samples. Each
+// sample is treated independently as a constant power dissipation. The input
+// is not validated; see SteadyStateChecked.
+func (self *Fixed) SteadyState(P []float64) []float64 {
+	nc, nn, no := self.nc, self.nn, self.no
+	ns := uint(len(P)) / nc
+
+	S := make([]float64, nn*ns)
+	Q := make([]float64, no*ns)
+
+	H, out, Y, qamb := self.H, self.out, self.Y, self.qamb
+	matrix.Multiply(H, P, S, nn, nc, ns)
+	for i := uint(0); i < ns; i++ {
+		Qi := Q[i*no : (i+1)*no]
+		for k := uint(0); k < no; k++ {
+			Qi[k] = qamb
+		}
+		observe(self.modal, out, Y, S[i*nn:(i+1)*nn], Qi)
 	}
 
 	return Q
 }
+
+// SteadyStateChecked is the same as SteadyState except that the input is
+// validated, and an error is returned if it is invalid.
+func (self *Fixed) SteadyStateChecked(P []float64) ([]float64, error) {
+	if _, err := check.Power(P, self.nc); err != nil {
+		return nil, err
+	}
+	return self.SteadyState(P), nil
+}
+
+// propagate adds to a state Si the contribution of a state Sj one time step
+// earlier.
+func (self *Fixed) propagate(Sj, Si []float64) {
+	nn, E := self.nn, self.E
+	if self.modal {
+		for i := uint(0); i < nn; i++ {
+			Si[i] += E[i] * Sj[i]
+		}
+		return
+	}
+	matrix.MultiplyAdd(E, Sj, Si, Si, nn, nn, 1)
+}
+
+// basis returns the eigenvectors of the system in the modal mode (see Modal in
+// Config) and nil otherwise, which identifies the basis of the states of the
+// integrator (see State).
+func (self *Fixed) basis() []float64 {
+	if self.modal {
+		return self.U
+	}
+	return nil
+}
+
+// phi2 returns (exp(x) - 1 - x) / x^2, which scales the contribution of power
+// changing linearly over a time step (see Interpolate in Config).
+func phi2(x float64) float64 {
+	if math.Abs(x) < 1e-3 {
+		return 0.5 + x*(1.0/6+x*(1.0/24+x/120))
+	}
+	return (math.Expm1(x) - x) / (x * x)
+}
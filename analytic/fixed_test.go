@@ -2,10 +2,12 @@ package analytic
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/ready-steady/assert"
 	"github.com/ready-steady/fixture"
+	"github.com/turing-complete/temperature/circuit"
 )
 
 func TestFixedNew(t *testing.T) {
@@ -22,6 +24,84 @@ func TestFixedNew(t *testing.T) {
 
 	assert.Close(temperature.E, fixtureE, 1e-9, t)
 	assert.Close(temperature.F, fixtureF, 1e-9, t)
+
+	assert.Equal(len(temperature.Sources), nc, t)
+	assert.Equal(temperature.Sources[1].Name, "core1", t)
+	assert.Equal(temperature.Sources[1].Left, 0.002, t)
+	assert.Equal(temperature.Targets, temperature.Sources, t)
+
+	assert.Equal(len(temperature.Nodes), 4*nc+12, t)
+	assert.Equal(temperature.Nodes[0].Name, "core0", t)
+}
+
+func TestFixedNewBlocks(t *testing.T) {
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+
+	blocks, _ := circuit.LoadFloorplan(config.Floorplan)
+	config.Floorplan, config.Configuration = "", ""
+	config.Blocks = blocks
+
+	temperature, err := NewFixed(config)
+	assert.Equal(err, nil, t)
+
+	assert.Close(temperature.D, fixtureD, 1e-14, t)
+	assert.Close(temperature.E, fixtureE, 1e-9, t)
+	assert.Close(temperature.F, fixtureF, 1e-9, t)
+
+	config.Specs = circuit.DefaultSpecs()
+	config.Specs.SinkSide = 0.01
+	_, err = NewFixed(config)
+	assert.Equal(err != nil, true, t)
+}
+
+func TestFixedNewStack(t *testing.T) {
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Floorplan, config.Stack = "", findFixture("002.lcf")
+
+	temperature, err := NewFixed(config)
+	assert.Equal(err, nil, t)
+
+	assert.Close(temperature.D, fixtureD, 1e-14, t)
+	assert.Close(temperature.E, fixtureE, 1e-9, t)
+	assert.Close(temperature.F, fixtureF, 1e-9, t)
+	assert.Equal(temperature.Nodes[2].Name, "layer1_core0", t)
+}
+
+func TestFixedNewInvalid(t *testing.T) {
+	load := func() *Config {
+		config := &Config{}
+		fixture.Load(findFixture("002.json"), config)
+		return config
+	}
+
+	config := load()
+	config.Ambience = math.NaN()
+	_, err := NewFixed(config)
+	assert.Equal(err != nil, true, t)
+
+	config = load()
+	config.Floorplan = findFixture("missing.flp")
+	_, err = NewFixed(config)
+	assert.Equal(err != nil, true, t)
+
+	config = load()
+	config.Configuration = findFixture("missing.config")
+	_, err = NewFixed(config)
+	assert.Equal(err != nil, true, t)
+
+	config = load()
+	config.Parameters = "-t_chip"
+	_, err = NewFixed(config)
+	assert.Equal(err != nil, true, t)
+
+	config = load()
+	config.Parameters = "-init_file missing.init"
+	temperature, err := NewFixed(config)
+	assert.Equal(err, nil, t)
+	_, err = temperature.Initial()
+	assert.Equal(err != nil, true, t)
 }
 
 func TestFixedCompute(t *testing.T) {
@@ -30,11 +110,200 @@ func TestFixedCompute(t *testing.T) {
 	)
 
 	temperature, P := loadFixed(nc)
-	Q := temperature.Compute(P)
+	Q, _ := temperature.Compute(P, nil)
 
 	assert.Close(Q, fixtureQ, 1e-12, t)
 }
 
+func TestFixedComputeModal(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Modal = true
+
+	temperature, err := NewFixed(config)
+	assert.Equal(err, nil, t)
+	assert.Equal(len(temperature.E), nn, t)
+
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+	nh := ns / 3
+
+	Q, _ := temperature.Compute(P, nil)
+	assert.Close(Q, fixtureQ, 1e-10, t)
+
+	Q1, S := temperature.Compute(P[:nh*nc], nil)
+	Q2, _ := temperature.Compute(P[nh*nc:], S)
+	assert.Equal(append(Q1, Q2...), Q, t)
+
+	nodal, _ := loadFixed(nc)
+	Q2, _ = nodal.Compute(P[nh*nc:], S)
+	assert.Close(Q2, Q[nh*nc:], 1e-10, t)
+
+	noop := func([]float64, []float64) {}
+	Q1, _, _ = temperature.ComputeWithStatic(P, nil, noop)
+	assert.Equal(Q1, Q, t)
+
+	Q = temperature.SteadyState([]float64{10, 20, 15, 5})
+	assert.Close(Q, fixtureQSteady, 1e-10, t)
+
+	config.AllNodes = true
+	temperature, _ = NewFixed(config)
+
+	Q, S = temperature.Compute(P, nil)
+	S1, _ := temperature.State(Q[(ns-1)*nn:])
+	assert.Close(S1.s, S.s, 1e-9, t)
+}
+
+func TestFixedPeak(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		ε  = 1e-6
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep
+	}
+
+	fluid, _ := NewFluid(config)
+	Q1, T1, _, _ := fluid.Peak(P, time, nil, ε)
+
+	for _, modal := range []bool{false, true} {
+		config.Modal = modal
+		temperature, _ := NewFixed(config)
+		assert.Equal(temperature.Z == nil, !modal, t)
+
+		Q2, S1 := temperature.Compute(P, nil)
+
+		Q, T, S, err := temperature.Peak(P, nil, ε)
+		assert.Equal(err, nil, t)
+		assert.Equal(len(temperature.U), nn*nn, t)
+		assert.Close(Q, Q1, 1e-10, t)
+		assert.Close(T, T1, 1e-12, t)
+		assert.Close(S.s, S1.s, 1e-9, t)
+
+		for i := range Q2 {
+			assert.Equal(Q2[i] <= Q[i]+ε, true, t)
+		}
+
+		_, _, _, err = temperature.Peak(P, nil, -ε)
+		assert.Equal(err != nil, true, t)
+	}
+}
+
+func TestFixedPeriodicSteadyState(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep
+	}
+
+	fluid, _ := NewFluid(config)
+	Q1, _ := fluid.PeriodicSteadyState(P, time)
+
+	for _, modal := range []bool{false, true} {
+		config.Modal = modal
+		temperature, _ := NewFixed(config)
+
+		Q, err := temperature.PeriodicSteadyState(P)
+		assert.Equal(err, nil, t)
+		assert.Close(Q, Q1, 1e-9, t)
+
+		_, err = temperature.PeriodicSteadyState(nil)
+		assert.Equal(err != nil, true, t)
+	}
+}
+
+func TestFixedComputeInterpolate(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Interpolate = true
+
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+	nh := ns / 3
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep
+	}
+
+	fluid, _ := NewFluid(config)
+	Q1, _ := fluid.Compute(P, time, nil)
+
+	noop := func([]float64, []float64) {}
+	for _, modal := range []bool{false, true} {
+		config.Modal = modal
+		temperature, _ := NewFixed(config)
+		assert.Equal(len(temperature.G), len(temperature.F), t)
+
+		Q, _, err := temperature.ComputeChecked(P, nil)
+		assert.Equal(err, nil, t)
+		assert.Close(Q, Q1, 1e-10, t)
+
+		Q, _, _ = temperature.ComputeWithStatic(append([]float64(nil), P...), nil, noop)
+		assert.Close(Q, Q1, 1e-10, t)
+
+		Q, S := temperature.Compute(P[:nh*nc], nil)
+		Q2, _ := temperature.Compute(P[nh*nc:], S)
+		assert.Close(append(Q, Q2...), Q1, 1e-10, t)
+	}
+}
+
+func TestFixedPeriodicSteadyStateInterpolate(t *testing.T) {
+	const (
+		nc = 2
+		np = 1000
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Interpolate = true
+
+	P := append([]float64(nil), fixtureP...)
+
+	for _, modal := range []bool{false, true} {
+		config.Modal = modal
+		temperature, _ := NewFixed(config)
+
+		Q, err := temperature.PeriodicSteadyState(P)
+		assert.Equal(err, nil, t)
+
+		// The period is repeated until the temperature settles.
+		var Q1 []float64
+		var S *State
+		for i := 0; i < np; i++ {
+			Q1, S = temperature.Compute(P, S)
+		}
+		assert.Close(Q, Q1, 1e-9, t)
+	}
+}
+
 func TestFixedComputeWithStatic(t *testing.T) {
 	const (
 		nc = 2
@@ -42,11 +311,298 @@ func TestFixedComputeWithStatic(t *testing.T) {
 
 	temperature, P := loadFixed(nc)
 	noop := func([]float64, []float64) {}
-	Q := temperature.ComputeWithStatic(P, noop)
+	Q, _, _ := temperature.ComputeWithStatic(P, nil, noop)
 
 	assert.Close(Q, fixtureQ, 1e-12, t)
 }
 
+func TestFixedComputeInitial(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		q0 = 333.15
+	)
+
+	temperature, P := loadFixed(nc)
+	ns := uint(len(P)) / nc
+
+	Q0 := make([]float64, nn)
+	for i := range Q0 {
+		Q0[i] = q0
+	}
+
+	S0, _ := temperature.State(Q0)
+	S, err := temperature.Initial()
+	assert.Equal(err, nil, t)
+	assert.Equal(S, S0, t) // init_temp in hotspot.config
+
+	Q, _ := temperature.Compute(P, S0)
+	Q1, _ := temperature.Compute(make([]float64, nc*ns), S0)
+	for i := range Q1 {
+		Q1[i] += fixtureQ[i] - temperature.qamb
+	}
+	assert.Close(Q, Q1, 1e-10, t)
+
+	noop := func([]float64, []float64) {}
+	Q2, _, _ := temperature.ComputeWithStatic(P, S0, noop)
+	assert.Close(Q2, Q, 1e-12, t)
+}
+
+func TestFixedComputeResume(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+	)
+
+	temperature, P := loadFixed(nc)
+	ns := uint(len(P)) / nc
+	nh := ns / 3
+
+	Q, S := temperature.Compute(P[:nh*nc], nil)
+	S0 := append([]float64(nil), S.s...)
+	Q1, S1 := temperature.Compute(P[nh*nc:], S)
+	Q = append(Q, Q1...)
+	assert.Equal(S.s, S0, t)
+	assert.Equal(len(S1.s), nn, t)
+
+	Q1, S2 := temperature.Compute(P, nil)
+	assert.Equal(Q, Q1, t)
+	assert.Equal(S1, S2, t)
+
+	noop := func([]float64, []float64) {}
+	Q, S, _ = temperature.ComputeWithStatic(P[:nh*nc], nil, noop)
+	Q1, _, _ = temperature.ComputeWithStatic(P[nh*nc:], S, noop)
+	Q = append(Q, Q1...)
+
+	Q1, _, _ = temperature.ComputeWithStatic(P, nil, noop)
+	assert.Equal(Q, Q1, t)
+}
+
+func TestFixedComputeAllNodes(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.AllNodes = true
+
+	temperature, _ := NewFixed(config)
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+
+	Q, _ := temperature.Compute(P, nil)
+
+	assert.Equal(uint(len(Q)), nn*ns, t)
+	for i := uint(0); i < ns; i++ {
+		assert.Close(Q[i*nn:i*nn+nc], fixtureQ[i*nc:(i+1)*nc], 1e-12, t)
+	}
+
+	_, S := temperature.Compute(P, nil)
+	S1, _ := temperature.State(Q[(ns-1)*nn:])
+	assert.Close(S1.s, S.s, 1e-9, t)
+}
+
+func TestFixedComputeSubset(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Sources = []string{"core1"}
+	config.Targets = []string{"core0"}
+
+	temperature, _ := NewFixed(config)
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+
+	assert.Equal(temperature.Sources[0].Name, "core1", t)
+	assert.Equal(temperature.Targets[0].Name, "core0", t)
+
+	P1 := make([]float64, ns)
+	for i := uint(0); i < ns; i++ {
+		P1[i], P[i*nc] = P[i*nc+1], 0
+	}
+
+	full, _ := loadFixed(nc)
+
+	Q1, _ := temperature.Compute(P1, nil)
+	Q, _ := full.Compute(P, nil)
+	for i := uint(0); i < ns; i++ {
+		assert.Close(Q1[i], Q[i*nc], 1e-12, t)
+	}
+
+	config.Sources = []string{"core2"}
+	_, err := NewFixed(config)
+	assert.Equal(err != nil, true, t)
+}
+
+func TestFixedComputeInvalid(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature, P := loadFixed(nc)
+
+	_, _, err := temperature.ComputeChecked(P[:len(P)-1], nil)
+	assert.Equal(err != nil, true, t)
+
+	_, _, err = temperature.ComputeChecked([]float64{1, math.NaN()}, nil)
+	assert.Equal(err != nil, true, t)
+
+	_, _, err = temperature.ComputeChecked(P, &State{s: make([]float64, 1)})
+	assert.Equal(err != nil, true, t)
+
+	_, err = temperature.SteadyStateChecked([]float64{1, math.Inf(1)})
+	assert.Equal(err != nil, true, t)
+
+	_, err = temperature.State(make([]float64, 1))
+	assert.Equal(err != nil, true, t)
+
+	Q, S := temperature.Compute(nil, nil)
+	assert.Equal(Q, []float64{}, t)
+	assert.Equal(S == nil, true, t)
+	assert.Equal(temperature.SteadyState(nil), []float64{}, t)
+}
+
+func TestFixedComputeWithStaticRunaway(t *testing.T) {
+	const (
+		nc   = 2
+		qmax = 340
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Ceiling = qmax
+
+	temperature, _ := NewFixed(config)
+	P := append([]float64(nil), fixtureP...)
+
+	noop := func([]float64, []float64) {}
+	_, _, err := temperature.ComputeWithStatic(P, nil, noop)
+
+	k := 0
+	for fixtureQ[k] <= qmax {
+		k++
+	}
+
+	runaway, ok := err.(*RunawayError)
+	assert.Equal(ok, true, t)
+	assert.Equal(runaway.Sample, uint(k)/nc, t)
+	assert.Equal(runaway.Core, uint(k)%nc, t)
+	assert.Equal(runaway.Name, temperature.Sources[k%nc].Name, t)
+
+	config.Ceiling = 0
+	temperature, _ = NewFixed(config)
+
+	leak := func(Q, P []float64) {
+		for i := range P {
+			P[i] += math.Exp(Q[i] - 300)
+		}
+	}
+	_, _, err = temperature.ComputeWithStatic(P, nil, leak)
+
+	_, ok = err.(*RunawayError)
+	assert.Equal(ok, true, t)
+}
+
+func TestFixedComputeWithStaticDivergence(t *testing.T) {
+	const (
+		nc = 2
+		ns = 5000
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.TimeStep = 1e-2
+
+	temperature, _ := NewFixed(config)
+
+	leak := func(rate float64) func([]float64, []float64) {
+		return func(Q, P []float64) {
+			for i := range P {
+				P[i] += 2 * math.Exp(rate*(Q[i]-config.Ambience))
+			}
+		}
+	}
+	power := func() []float64 {
+		P := make([]float64, nc*ns)
+		for i := range P {
+			P[i] = 5
+		}
+		return P
+	}
+
+	// The temperature settles at the fixed point.
+	P := power()
+	Q, _, err := temperature.ComputeWithStatic(P, nil, leak(0.03))
+	assert.Equal(err, nil, t)
+	assert.Close(Q[(ns-1)*nc:], temperature.SteadyState(P[(ns-1)*nc:]), 1e-2, t)
+
+	// The temperature diverges while staying finite.
+	_, _, err = temperature.ComputeWithStatic(power(), nil, leak(0.05))
+	runaway, ok := err.(*RunawayError)
+	assert.Equal(ok, true, t)
+	assert.Equal(runaway.Sample > 0, true, t)
+	assert.Equal(runaway.Temperature < 400, true, t)
+}
+
+func TestFixedSteadyState(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature, _ := loadFixed(nc)
+	Q := temperature.SteadyState([]float64{10, 20, 15, 5})
+
+	assert.Close(Q, fixtureQSteady, 1e-10, t)
+}
+
+func TestFixedSteadyStateSecondary(t *testing.T) {
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+
+	blocks, _ := circuit.LoadFloorplan(config.Floorplan)
+	config.Floorplan, config.Configuration = "", ""
+	config.Blocks = blocks
+	config.Specs = circuit.DefaultSpecs()
+
+	P := []float64{10, 20, 15, 5}
+
+	temperature, _ := NewFixed(config)
+	Q1 := temperature.SteadyState(P)
+
+	config.Specs.Secondary = true
+
+	temperature, err := NewFixed(config)
+	assert.Equal(err, nil, t)
+	assert.Equal(temperature.nn, uint(9*2+12+16), t)
+
+	// An additional heat path can redistribute the heat between the blocks,
+	// but the total heat weighted by the power can only go down.
+	Q2 := temperature.SteadyState(P)
+	sum1, sum2 := 0.0, 0.0
+	for i := range Q2 {
+		assert.Equal(Q2[i] > config.Ambience, true, t)
+		sum1 += P[i] * (Q1[i] - config.Ambience)
+		sum2 += P[i] * (Q2[i] - config.Ambience)
+	}
+	assert.Equal(sum2 < sum1, true, t)
+
+	// Without the heat sink, the whole power has to pass the convection
+	// resistance of the circuit board.
+	config.Specs.ConvectionResistance = 1e6
+
+	temperature, _ = NewFixed(config)
+	Q2 = temperature.SteadyState([]float64{0.5, 0.5})
+	for i := range Q2 {
+		assert.Equal(Q2[i] > config.Ambience+config.Specs.SecondaryConvectionResistance, true, t)
+		assert.Equal(Q2[i] < math.Inf(1), true, t)
+	}
+}
+
 func BenchmarkFixedCompute002(b *testing.B) {
 	const (
 		nc = 2
@@ -57,7 +613,7 @@ func BenchmarkFixedCompute002(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		temperature.Compute(P)
+		temperature.Compute(P, nil)
 	}
 }
 
@@ -72,7 +628,7 @@ func BenchmarkFixedComputeWithStatic002(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		temperature.ComputeWithStatic(P, noop)
+		temperature.ComputeWithStatic(P, nil, noop)
 	}
 }
 
@@ -88,7 +644,27 @@ func BenchmarkFixedCompute032(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		temperature.Compute(P)
+		temperature.Compute(P, nil)
+	}
+}
+
+func BenchmarkFixedComputeModal032(b *testing.B) {
+	const (
+		nc = 32
+		ns = 1000
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("032.json"), config)
+	config.Modal = true
+
+	temperature, _ := NewFixed(config)
+	P := random(nc*ns, 0, 20)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		temperature.Compute(P, nil)
 	}
 }
 
@@ -1214,3 +1214,8 @@ var fixtureQ = []float64{
 	3.344075343852903e+02, 3.278647179354933e+02,
 	3.343293345941553e+02, 3.224487606241598e+02,
 }
+
+var fixtureQSteady = []float64{
+	+3.4460360503230710e+02, +3.6323923489247010e+02,
+	+3.5131542823834050e+02, +3.3267979837817760e+02,
+}
@@ -1,57 +1,108 @@
 package analytic
 
 import (
+	"errors"
 	"math"
 
-	"github.com/ready-steady/linear/decomposition"
 	"github.com/ready-steady/linear/matrix"
-	"github.com/turing-complete/hotspot"
+	"github.com/turing-complete/temperature/circuit"
+	"github.com/turing-complete/temperature/internal/check"
+	"github.com/turing-complete/temperature/internal/runaway"
 )
 
 // Fluid is an integrator of a thermal system with a fluid time step.
 type Fluid struct {
 	nc uint
 	nn uint
+	no uint
+
+	in    [][]circuit.Link
+	out   [][]circuit.Link
+	probe [][]circuit.Link
+
+	modal bool
 
 	D []float64
 	U []float64
+	V []float64
 	Λ []float64
 
+	// The matrices mapping the state of the system in the eigenbasis to the
+	// temperature of the targets and of the sources (see observation). Z is
+	// used in the modal mode only (see Modal in Config).
+	Y []float64
+	Z []float64
+
+	cache *cache
+
+	interpolate bool
+
+	// The blocks dissipating power, which correspond to the rows of power
+	// profiles.
+	Sources []circuit.Block
+
+	// The blocks whose temperature is observed, which correspond to the rows
+	// of temperature profiles unless all nodes are observed (see Config).
+	Targets []circuit.Block
+
+	// The thermal nodes of the system.
+	Nodes []circuit.Node
+
+	// The steady-state temperature rise of the sources per unit power of the
+	// sources, which is used to detect thermal runaway (see resistance).
+	R []float64
+
 	qamb float64
+	qmax float64
+
+	// The initial temperature of the thermal nodes (see Initial).
+	initial func() ([]float64, error)
 }
 
 // NewFluid returns a new integrator.
 func NewFluid(config *Config) (*Fluid, error) {
-	model := hotspot.New((*hotspot.Config)(&config.Config))
-	nc, nn := model.Cores, model.Nodes
-
-	A := model.G // Reuse model.G to store A.
-	D := model.C // Reuse model.C to store D.
-	for i := uint(0); i < nn; i++ {
-		D[i] = math.Sqrt(1.0 / model.C[i])
-	}
-	for i := uint(0); i < nn; i++ {
-		for j := uint(0); j < nn; j++ {
-			A[j*nn+i] = -D[i] * D[j] * A[j*nn+i]
-		}
-	}
-
-	U := A // Reuse A (which is model.G) to store U.
-	Λ := make([]float64, nn)
-	if err := decomposition.SymmetricEigen(A, U, Λ, nn); err != nil {
+	system, err := decompose(config)
+	if err != nil {
 		return nil, err
 	}
+	nn, U := system.nn, system.U
 
 	temperature := &Fluid{
-		nc: nc,
+		nc: system.nc,
 		nn: nn,
+		no: system.no,
 
-		D: D,
+		in:    system.in,
+		out:   system.out,
+		probe: system.probe,
 
-		Λ: Λ,
+		modal: config.Modal,
+
+		D: system.D,
+
+		Λ: system.Λ,
 		U: U,
+		V: project(U, system.in, nn),
+		Y: observation(U, system.out, nn),
+
+		Sources: system.sources,
+		Targets: system.targets,
+		Nodes:   system.nodes,
+
+		R: system.R,
+
+		initial: system.initial,
 
 		qamb: config.Ambience,
+		qmax: config.Ceiling,
+
+		interpolate: config.Interpolate,
+	}
+	if config.Modal {
+		temperature.Z = observation(U, system.probe, nn)
+	}
+	if !config.Modal && config.CacheSize > 0 {
+		temperature.cache = newCache(config.CacheSize)
 	}
 
 	return temperature, nil
@@ -60,51 +111,543 @@ func NewFluid(config *Config) (*Fluid, error) {
 // Compute calculates the temperature profile corresponding to a power profile.
 //
 // The power profile is specified by a matrix P containing power samples and a
-// vector ΔT assigning durations to each of the samples.
-func (self *Fluid) Compute(P, ΔT []float64) []float64 {
-	nc, nn, ns := self.nc, self.nn, uint(len(ΔT))
+// vector ΔT assigning durations to each of the samples. The initial state of
+// the system is specified by S0 (see State); if S0 is nil, the system starts at
+// the ambient temperature. The final state of the system is returned along
+// with the temperature profile so that the computation can be resumed by
+// passing the state to a subsequent call.
+//
+// The input is not validated; see ComputeChecked.
+func (self *Fluid) Compute(P, ΔT []float64, S0 *State) ([]float64, *State) {
+	Q, S, _ := self.compute(P, ΔT, S0, nil)
+	return Q, S
+}
 
-	D, U, Λ, qamb := self.D, self.U, self.Λ, self.qamb
+// ComputeChecked is the same as Compute except that the input is validated,
+// and an error is returned if it is invalid.
+func (self *Fluid) ComputeChecked(P, ΔT []float64, S0 *State) ([]float64, *State, error) {
+	if err := self.check(P, ΔT, S0); err != nil {
+		return nil, nil, err
+	}
+	Q, S := self.Compute(P, ΔT, S0)
+	return Q, S, nil
+}
 
-	diag := make([]float64, nn)
-	temp := make([]float64, nn*nn)
+// ComputeWithStatic calculates the temperature profile and the total power
+// profile corresponding to a dynamic power profile taking into account the
+// static power.
+//
+// The dynamic power profile is specified by a matrix P containing power samples
+// and a vector ΔT assigning durations to each of the samples. The dynamic power
+// profile is overwritten with the total power profile. The initial state of the
+// system is specified by S0 (see State); if S0 is nil, the system starts at the
+// ambient temperature. The final state of the system is returned along with
+// the temperature profile so that the computation can be resumed by passing
+// the state to a subsequent call.
+//
+// The static power is specified by a function leak(Q, P) that receives the
+// temperature Q of the blocks dissipating power (see Sources) and adds the
+// corresponding static power to the power P of these blocks. The temperature
+// is taken at the beginning of each sample. As in Fixed, the function is also
+// called with perturbed temperature to detect thermal runaway, so it should be
+// free of side effects. If thermal runaway is detected (see Ceiling in
+// Config), a RunawayError is returned.
+func (self *Fluid) ComputeWithStatic(P, ΔT []float64, S0 *State,
+	leak func([]float64, []float64)) ([]float64, *State, error) {
 
-	E := make([]float64, nn*nn)
-	F := make([]float64, nn*nc)
+	if err := self.check(P, ΔT, S0); err != nil {
+		return nil, nil, err
+	}
+	return self.compute(P, ΔT, S0, leak)
+}
+
+// ComputeAt calculates the temperature at particular time moments
+// corresponding to a power profile, which reveals the behavior of the
+// temperature within the samples of the power profile.
+//
+// The power profile is specified by a matrix P containing power samples and a
+// vector ΔT assigning durations to each of the samples. The time moments are
+// specified by a vector T of nondecreasing values measured from the beginning
+// of the power profile and not exceeding its total duration (see Subdivide).
+// The i-th column of the result corresponds to the moment T[i]. The initial
+// state of the system is specified by S0 (see State); if S0 is nil, the system
+// starts at the ambient temperature. The state of the system at the end of the
+// power profile is returned as well.
+func (self *Fluid) ComputeAt(P, ΔT, T []float64, S0 *State) ([]float64, *State, error) {
+	nc, nn, no := self.nc, self.nn, self.no
+	if err := self.check(P, ΔT, S0); err != nil {
+		return nil, nil, err
+	}
+	ns := uint(len(ΔT))
+	total := 0.0
+	for _, Δt := range ΔT {
+		total += Δt
+	}
+	if err := check.Time(T, total); err != nil {
+		return nil, nil, err
+	}
+
+	U, V, Y, Λ, qamb := self.U, self.V, self.Y, self.Λ, self.qamb
+
+	// The state is evaluated within the samples in the eigenbasis regardless
+	// of the mode.
+	S := make([]float64, nn)
+	copy(S, S0.in(U, nn))
 
-	S1 := make([]float64, nn)
-	S2 := make([]float64, nn)
+	E := make([]float64, nn)
+	F := make([]float64, nn)
+	var G []float64
+	if self.interpolate {
+		G = make([]float64, nn)
+	}
+	Δtp := math.NaN()
 
-	Q := make([]float64, nc*ns)
+	// The power in the eigenbasis of the current and previous samples.
+	W := make([]float64, nn)
+	var Wp []float64
+	if self.interpolate && ns > 0 {
+		Wp = make([]float64, nn)
+		matrix.Multiply(V, S0.power(P, nc), Wp, nn, nc, 1)
+	}
+	X := make([]float64, nn)
 
+	nq := uint(len(T))
+	Q := make([]float64, no*nq)
+	for i := range Q {
+		Q[i] = qamb
+	}
+
+	k, t0 := uint(0), 0.0
 	for i := uint(0); i < ns; i++ {
-		Δt := ΔT[i]
+		Δt, Pi := ΔT[i], P[i*nc:(i+1)*nc]
+		t1 := t0 + Δt
 
-		for j := uint(0); j < nn; j++ {
-			diag[j] = math.Exp(Δt * Λ[j])
-			for k := uint(0); k < nn; k++ {
-				temp[k*nn+j] = diag[j] * U[j*nn+k]
+		matrix.Multiply(V, Pi, W, nn, nc, 1)
+		W0 := W
+		if self.interpolate {
+			W0 = Wp
+		}
+
+		// The state within the sample is given by the same closed-form
+		// solution as the one at the end of the sample.
+		for ; k < nq && (T[k] <= t1 || i+1 == ns); k++ {
+			τ := math.Max(T[k]-t0, 0)
+			for j := uint(0); j < nn; j++ {
+				e := math.Exp(τ * Λ[j])
+				X[j] = e*S[j] + (e-1.0)/Λ[j]*W0[j]
+				if self.interpolate && Δt > 0 {
+					X[j] += τ * τ / Δt * phi2(τ*Λ[j]) * (W[j] - Wp[j])
+				}
 			}
+			Qk := Q[k*no : (k+1)*no]
+			matrix.MultiplyAdd(Y, X, Qk, Qk, no, nn, 1)
 		}
-		matrix.Multiply(U, temp, E, nn, nn, nn)
 
-		for j := uint(0); j < nn; j++ {
-			diag[j] = (diag[j] - 1.0) / Λ[j]
+		if Δt != Δtp {
+			self.diagonals(Δt, E, F, G)
+			Δtp = Δt
+		}
+		advance(S, E, F, G, W, Wp)
+		if self.interpolate {
+			W, Wp = Wp, W
+		}
+
+		t0 = t1
+	}
+	for ; k < nq; k++ {
+		Qk := Q[k*no : (k+1)*no]
+		matrix.MultiplyAdd(Y, S, Qk, Qk, no, nn, 1)
+	}
+
+	S1 := &State{Power: append([]float64(nil), last(P, nc, S0)...), s: S, u: U}
+
+	return Q, S1.to(self.basis(), nn), nil
+}
+
+// Peak calculates the peak temperature within each sample of a power profile
+// and the time moments at which the peaks are attained.
+//
+// The power profile is specified by a matrix P containing power samples and a
+// vector ΔT assigning durations to each of the samples. The result is a pair of
+// matrices whose i-th columns contain the peak temperature of the targets
+// within the i-th sample, computed with an absolute error of at most ε, and
+// the time moments of the peaks measured from the beginning of the power
+// profile. The initial state of the system is specified by S0 (see State); if
+// S0 is nil, the system starts at the ambient temperature. The final state of
+// the system is returned as well so that the computation can be resumed by
+// passing the state to a subsequent call.
+func (self *Fluid) Peak(P, ΔT []float64, S0 *State, ε float64) ([]float64, []float64,
+	*State, error) {
+
+	nc, nn, no := self.nc, self.nn, self.no
+	if err := self.check(P, ΔT, S0); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := check.Tolerance(ε); err != nil {
+		return nil, nil, nil, err
+	}
+
+	U := self.U
+
+	S := make([]float64, nn)
+	copy(S, S0.in(U, nn))
+
+	var P0 []float64
+	if self.interpolate {
+		P0 = S0.power(P, nc)
+	}
+	Q, T := peaks(self.Λ, self.V, self.Y, P, P0, ΔT, S, nc, nn, no, self.qamb, ε)
+
+	S1 := &State{Power: append([]float64(nil), last(P, nc, S0)...), s: S, u: U}
+
+	return Q, T, S1.to(self.basis(), nn), nil
+}
+
+// Subdivide returns the time moments dividing each sample of a power profile
+// into equal parts not longer than a particular resolution δt (see ComputeAt).
+// The samples are specified by a vector ΔT of their durations. The moments
+// are measured from the beginning of the power profile and include the end of
+// each sample. If δt is not positive, only the ends of the samples are
+// returned.
+func Subdivide(ΔT []float64, δt float64) []float64 {
+	T := []float64{}
+	t0 := 0.0
+	for _, Δt := range ΔT {
+		n := 1.0
+		if δt > 0 {
+			n = math.Max(math.Ceil(Δt/δt), 1)
+		}
+		t1 := t0 + Δt
+		for j := 1.0; j < n; j++ {
+			T = append(T, t0+Δt*j/n)
+		}
+		T = append(T, t1)
+		t0 = t1
+	}
+	return T
+}
+
+func (self *Fluid) compute(P, ΔT []float64, S0 *State,
+	leak func([]float64, []float64)) ([]float64, *State, error) {
+
+	nc, nn, no := self.nc, self.nn, self.no
+	ns := uint(len(ΔT))
+
+	V, Y, Z, qamb := self.V, self.Y, self.Z, self.qamb
+	out, probe, modal := self.out, self.probe, self.modal
+
+	S := make([]float64, nn)
+	copy(S, S0.in(self.basis(), nn))
+
+	// The diagonals of the propagation matrices in the modal mode (see
+	// diagonals) and the propagation matrices otherwise (see matrices).
+	var E, F, G []float64
+	if modal {
+		E = make([]float64, nn)
+		F = make([]float64, nn)
+		if self.interpolate {
+			G = make([]float64, nn)
+		}
+	}
+	Δtp := math.NaN()
+
+	// The power in the eigenbasis of the current and previous samples in the
+	// modal mode and the next state otherwise.
+	W := make([]float64, nn)
+	var Wp []float64
+	if modal && self.interpolate {
+		Wp = make([]float64, nn)
+	}
+	Q := make([]float64, no*ns)
+
+	L := make([]float64, nc)
+	for k := uint(0); k < nc; k++ {
+		L[k] = qamb
+	}
+	observe(modal, probe, Z, S, L)
+
+	var detector *runaway.Detector
+	if leak != nil {
+		detector = runaway.New(self.R, qamb, self.qmax, self.Sources)
+	}
+
+	for i := uint(0); i < ns; i++ {
+		Δt, Pi, Qi := ΔT[i], P[i*nc:(i+1)*nc], Q[i*no:(i+1)*no]
+
+		if leak != nil {
+			leak(L, Pi)
+			if err := detector.Check(i, L, Pi, leak); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if Δt != Δtp {
+			if modal {
+				self.diagonals(Δt, E, F, G)
+			} else {
+				E, F, G = self.matrices(Δt)
+			}
+			Δtp = Δt
+		}
+
+		if modal {
+			// The power preceding the first sample is taken after leak since
+			// it is the first sample itself unless S0 carries power.
+			if i == 0 && Wp != nil {
+				matrix.Multiply(V, S0.power(P, nc), Wp, nn, nc, 1)
+			}
+			matrix.Multiply(V, Pi, W, nn, nc, 1)
+			advance(S, E, F, G, W, Wp)
+			if self.interpolate {
+				W, Wp = Wp, W
+			}
+		} else {
+			matrix.Multiply(F, Pi, W, nn, nc, 1)
+			if G != nil {
+				Pj := S0.power(P, nc)
+				if i > 0 {
+					Pj = P[(i-1)*nc : i*nc]
+				}
+				matrix.MultiplyAdd(G, Pj, W, W, nn, nc, 1)
+			}
+			matrix.MultiplyAdd(E, S, W, W, nn, nn, 1)
+			S, W = W, S
+		}
+
+		for k := uint(0); k < no; k++ {
+			Qi[k] = qamb
+		}
+		observe(modal, out, Y, S, Qi)
+
+		if leak != nil {
 			for k := uint(0); k < nc; k++ {
-				temp[k*nn+j] = diag[j] * U[j*nn+k] * D[k]
+				L[k] = qamb
+			}
+			observe(modal, probe, Z, S, L)
+			if err := detector.Check(i, L, nil, nil); err != nil {
+				return nil, nil, err
 			}
 		}
-		matrix.Multiply(U, temp, F, nn, nn, nc)
+	}
+
+	return Q, &State{Power: append([]float64(nil), last(P, nc, S0)...), s: S,
+		u: self.basis()}, nil
+}
+
+// check validates a power profile given by a matrix P and a vector ΔT along
+// with an initial state S0.
+func (self *Fluid) check(P, ΔT []float64, S0 *State) error {
+	ns, err := check.Power(P, self.nc)
+	if err != nil {
+		return err
+	}
+	if err := S0.check(self.nc, self.nn); err != nil {
+		return err
+	}
+	return check.Duration(ΔT, ns)
+}
+
+// basis returns the eigenvectors of the system in the modal mode (see Modal in
+// Config) and nil otherwise, which identifies the basis of the states of the
+// integrator (see State).
+func (self *Fluid) basis() []float64 {
+	if self.modal {
+		return self.U
+	}
+	return nil
+}
+
+// advance propagates a state S of the system in the eigenbasis over a time step
+// given the diagonals of the propagation matrices (see diagonals) and the power
+// W of the current sample and Wp of the previous one in the eigenbasis.
+func advance(S, E, F, G, W, Wp []float64) {
+	for j := range S {
+		S[j] = E[j]*S[j] + F[j]*W[j]
+	}
+	if G != nil {
+		for j := range S {
+			S[j] += G[j] * Wp[j]
+		}
+	}
+}
+
+// diagonals computes the diagonals of the matrices E, F, and G propagating the
+// state of the system in the eigenbasis over a time step. F is applied to the
+// current power sample, and G is applied to the previous one when power is
+// interpolated between samples (see Interpolate in Config); otherwise, G is
+// nil.
+func (self *Fluid) diagonals(Δt float64, E, F, G []float64) {
+	nn, Λ := self.nn, self.Λ
+	for j := uint(0); j < nn; j++ {
+		E[j] = math.Exp(Δt * Λ[j])
+		F[j] = (E[j] - 1.0) / Λ[j]
+	}
+	if G != nil {
+		for j := uint(0); j < nn; j++ {
+			g := Δt * phi2(Δt*Λ[j])
+			F[j], G[j] = g, F[j]-g
+		}
+	}
+}
+
+// matrices returns the matrices E, F, and G propagating the state of the
+// system in the basis of the thermal nodes over a time step (see diagonals).
+// If the cache is enabled (see CacheSize in Config), the matrices are taken
+// from the cache or computed and stored in the cache.
+func (self *Fluid) matrices(Δt float64) ([]float64, []float64, []float64) {
+	nc, nn, U, V := self.nc, self.nn, self.U, self.V
+
+	if self.cache != nil {
+		if E, F, G, ok := self.cache.get(Δt); ok {
+			return E, F, G
+		}
+	}
+
+	e := make([]float64, nn)
+	f := make([]float64, nn)
+	var g []float64
+	if self.interpolate {
+		g = make([]float64, nn)
+	}
+	self.diagonals(Δt, e, f, g)
+
+	temp := make([]float64, nn*nn)
 
-		matrix.Multiply(F, P[i*nc:(i+1)*nc], S1, nn, nc, 1)
-		matrix.MultiplyAdd(E, S2, S1, S1, nn, nn, 1)
+	E := make([]float64, nn*nn)
+	for i := uint(0); i < nn; i++ {
+		for j := uint(0); j < nn; j++ {
+			temp[j*nn+i] = e[i] * U[i*nn+j]
+		}
+	}
+	matrix.Multiply(U, temp, E, nn, nn, nn)
 
+	F := make([]float64, nn*nc)
+	for i := uint(0); i < nn; i++ {
 		for j := uint(0); j < nc; j++ {
-			Q[i*nc+j] = D[j]*S1[j] + qamb
+			temp[j*nn+i] = f[i] * V[j*nn+i]
+		}
+	}
+	matrix.Multiply(U, temp, F, nn, nn, nc)
+
+	var G []float64
+	if g != nil {
+		G = make([]float64, nn*nc)
+		for i := uint(0); i < nn; i++ {
+			for j := uint(0); j < nc; j++ {
+				temp[j*nn+i] = g[i] * V[j*nn+i]
+			}
 		}
+		matrix.Multiply(U, temp, G, nn, nn, nc)
+	}
+
+	if self.cache != nil {
+		self.cache.put(Δt, E, F, G)
+	}
+
+	return E, F, G
+}
+
+// State calculates the state of the system corresponding to the temperature of
+// the thermal nodes.
+//
+// The temperature is specified by a vector Q containing one value per thermal
+// node. The result can be used as the initial state in Compute. In the modal
+// mode (see Modal in Config), the state is expressed in the eigenbasis.
+func (self *Fluid) State(Q []float64) (*State, error) {
+	nn := self.nn
+	if err := check.Temperature(Q, nn); err != nil {
+		return nil, err
+	}
+	return temperatureState(Q, self.D, self.basis(), self.qamb, nn), nil
+}
+
+// Initial returns the state of the system corresponding to the initial
+// temperature of the thermal nodes given by the configuration of the thermal
+// RC circuit (see InitialTemperature and InitialFile in circuit.Specs). The
+// result can be used as the initial state in Compute. An error reading the
+// file with the initial temperature is reported here.
+func (self *Fluid) Initial() (*State, error) {
+	Q, err := self.initial()
+	if err != nil {
+		return nil, err
+	}
+	return self.State(Q)
+}
+
+// PeriodicSteadyState calculates the temperature profile corresponding to a
+// power profile that is repeated indefinitely once the temperature has settled
+// into the periodic regime.
+//
+// The power profile is specified by a matrix P containing power samples and a
+// vector ΔT assigning durations to each of the samples. The result covers one
+// period of the power profile. When power is interpolated between samples (see
+// Interpolate in Config), the last sample precedes the first one.
+func (self *Fluid) PeriodicSteadyState(P, ΔT []float64) ([]float64, error) {
+	nc, nn, U, Λ := self.nc, self.nn, self.U, self.Λ
 
-		S1, S2 = S2, S1
+	if err := self.check(P, ΔT, nil); err != nil {
+		return nil, err
+	}
+
+	total := 0.0
+	for _, Δt := range ΔT {
+		total += Δt
+	}
+	if total <= 0 {
+		return nil, errors.New("the duration of the power profile should be positive")
+	}
+
+	// The power preceding the first sample.
+	Pp := P[uint(len(P))-nc:]
+
+	// The state at the end of the period starting from the ambience.
+	_, S0 := self.Compute(P, ΔT, &State{Power: Pp})
+	S := S0.in(U, nn)
+
+	// The periodic state solves S0 = exp(total * Λ) * S0 + S in the eigenbasis.
+	for j := uint(0); j < nn; j++ {
+		S[j] /= -math.Expm1(total * Λ[j])
+	}
+
+	Q, _ := self.Compute(P, ΔT, &State{Power: Pp, s: S, u: U})
+	return Q, nil
+}
+
+// SteadyState calculates the steady-state temperature profile corresponding to
+// a power profile.
+//
+// The power profile is specified by a matrix P containing power samples. Each
+// sample is treated independently as a constant power dissipation. The input
+// is not validated; see SteadyStateChecked.
+func (self *Fluid) SteadyState(P []float64) []float64 {
+	nc, nn, no := self.nc, self.nn, self.no
+	ns := uint(len(P)) / nc
+
+	V, Y, Λ, qamb := self.V, self.Y, self.Λ, self.qamb
+
+	S := make([]float64, nn*ns)
+	Q := make([]float64, no*ns)
+
+	matrix.Multiply(V, P, S, nn, nc, ns)
+	for i := uint(0); i < ns; i++ {
+		Si, Qi := S[i*nn:(i+1)*nn], Q[i*no:(i+1)*no]
+		for j := uint(0); j < nn; j++ {
+			Si[j] /= -Λ[j]
+		}
+		for k := uint(0); k < no; k++ {
+			Qi[k] = qamb
+		}
+		matrix.MultiplyAdd(Y, Si, Qi, Qi, no, nn, 1)
 	}
 
 	return Q
 }
+
+// SteadyStateChecked is the same as SteadyState except that the input is
+// validated, and an error is returned if it is invalid.
+func (self *Fluid) SteadyStateChecked(P []float64) ([]float64, error) {
+	if _, err := check.Power(P, self.nc); err != nil {
+		return nil, err
+	}
+	return self.SteadyState(P), nil
+}
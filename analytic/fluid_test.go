@@ -23,6 +23,10 @@ func TestFluidNew(t *testing.T) {
 
 	assert.Close(abs(temperature.U), abs(fixtureU), 1e-9, t)
 	assert.Close(temperature.Λ, fixtureΛ, 1e-9, t)
+
+	assert.Equal(len(temperature.Sources), nc, t)
+	assert.Equal(len(temperature.Targets), nc, t)
+	assert.Equal(len(temperature.Nodes), 4*nc+12, t)
 }
 
 func TestFluidCompute(t *testing.T) {
@@ -38,11 +42,608 @@ func TestFluidCompute(t *testing.T) {
 		time[i] = config.TimeStep
 	}
 
-	Q := temperature.Compute(P, time)
+	Q, _ := temperature.Compute(P, time, nil)
 
 	assert.Close(Q, fixtureQ, 1e-12, t)
 }
 
+func TestFluidComputeCache(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature, config, P := loadFluid(nc)
+	ns := uint(len(P) / nc)
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep * float64(1+i%3)
+	}
+
+	Q, _ := temperature.Compute(P, time, nil)
+
+	config.CacheSize = 2
+	temperature, _ = NewFluid(config)
+
+	Q1, _ := temperature.Compute(P, time, nil)
+	assert.Equal(Q1, Q, t)
+
+	config.CacheSize = 3
+	temperature, _ = NewFluid(config)
+
+	Q1, _ = temperature.Compute(P, time, nil)
+	assert.Equal(Q1, Q, t)
+	Q1, _ = temperature.Compute(P, time, nil)
+	assert.Equal(Q1, Q, t)
+
+	E1, _, _ := temperature.matrices(time[0])
+	E2, _, _ := temperature.matrices(time[0])
+	assert.Equal(&E1[0] == &E2[0], true, t)
+
+	config.Modal = true
+	temperature, _ = NewFluid(config)
+	assert.Equal(temperature.cache == nil, true, t)
+}
+
+func TestFluidComputeModal(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature, config, P := loadFluid(nc)
+	ns := uint(len(P) / nc)
+	nh := ns / 3
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep * float64(1+i%3)
+	}
+
+	Q, _ := temperature.Compute(P, time, nil)
+
+	config.Modal = true
+	modal, _ := NewFluid(config)
+
+	Q1, S1 := modal.Compute(P, time, nil)
+	assert.Close(Q1, Q, 1e-10, t)
+	assert.Equal(len(S1.u), len(modal.U), t)
+
+	// The state is converted when it is passed to the other integrator.
+	_, S := modal.Compute(P[:nh*nc], time[:nh], nil)
+	Q1, _ = temperature.Compute(P[nh*nc:], time[nh:], S)
+	assert.Close(Q1, Q[nh*nc:], 1e-10, t)
+
+	_, S = temperature.Compute(P[:nh*nc], time[:nh], nil)
+	Q1, _ = modal.Compute(P[nh*nc:], time[nh:], S)
+	assert.Close(Q1, Q[nh*nc:], 1e-10, t)
+}
+
+func TestFluidComputeInterpolate(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		nd = 200
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Interpolate = true
+
+	temperature, _ := NewFluid(config)
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep * float64(1+i%3)
+	}
+
+	Q, S, err := temperature.ComputeChecked(P, time, nil)
+	assert.Equal(err, nil, t)
+
+	// Linear power is approximated by constant power over short time steps.
+	P1, time1 := []float64{}, []float64{}
+	for i := uint(0); i < ns; i++ {
+		j := i
+		if i > 0 {
+			j = i - 1
+		}
+		for l := 0; l < nd; l++ {
+			α := (float64(l) + 0.5) / nd
+			for k := uint(0); k < nc; k++ {
+				P1 = append(P1, (1-α)*P[j*nc+k]+α*P[i*nc+k])
+			}
+			time1 = append(time1, time[i]/nd)
+		}
+	}
+
+	fluid, _, _ := loadFluid(nc)
+	Q1, _ := fluid.Compute(P1, time1, nil)
+	for i := uint(0); i < ns; i++ {
+		assert.Close(Q[i*nc:(i+1)*nc], Q1[((i+1)*nd-1)*nc:(i+1)*nd*nc], 1e-3, t)
+	}
+
+	Q1, _ = fluid.Compute(P, time, nil)
+	Δ := 0.0
+	for i := range Q {
+		Δ = math.Max(Δ, math.Abs(Q1[i]-Q[i]))
+	}
+	assert.Equal(Δ > 1e-3, true, t)
+
+	config.CacheSize = 2
+	temperature, _ = NewFluid(config)
+
+	Q1, _ = temperature.Compute(P, time, nil)
+	assert.Equal(Q1, Q, t)
+
+	Q1, _, _ = temperature.ComputeAt(P, time, Subdivide(time, 0), nil)
+	assert.Close(Q1, Q, 1e-10, t)
+
+	nh := ns / 3
+	Q1, S1 := temperature.Compute(P[:nh*nc], time[:nh], nil)
+	Q2, _ := temperature.Compute(P[nh*nc:], time[nh:], S1)
+	assert.Close(append(Q1, Q2...), Q, 1e-10, t)
+
+	Q1, T, S1, _ := temperature.Peak(P, time, nil, 1e-6)
+	assert.Close(S1.s, S.s, 1e-12, t)
+	for i := range Q {
+		assert.Equal(Q[i] <= Q1[i]+1e-6, true, t)
+	}
+
+	for k := uint(0); k < nc; k++ {
+		Tk := make([]float64, ns)
+		for i := uint(0); i < ns; i++ {
+			Tk[i] = T[i*nc+k]
+		}
+		Q2, _, _ := temperature.ComputeAt(P, time, Tk, nil)
+		for i := uint(0); i < ns; i++ {
+			assert.Close(Q2[i*nc+k], Q1[i*nc+k], 1e-10, t)
+		}
+	}
+}
+
+func TestFluidComputeAt(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		nd = 4
+	)
+
+	temperature, config, P := loadFluid(nc)
+	ns := uint(len(P) / nc)
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = nd * config.TimeStep * float64(1+i%3)
+	}
+
+	Q1, S1 := temperature.Compute(P, time, nil)
+
+	Q, S, err := temperature.ComputeAt(P, time, Subdivide(time, 0), nil)
+	assert.Equal(err, nil, t)
+	assert.Close(Q, Q1, 1e-12, t)
+	assert.Equal(S.u == nil, true, t)
+	assert.Close(S.s, S1.s, 1e-11, t)
+
+	config.Modal = true
+	modal, _ := NewFluid(config)
+
+	_, S1 = modal.Compute(P, time, nil)
+	_, S, _ = modal.ComputeAt(P, time, Subdivide(time, 0), nil)
+	assert.Equal(S, S1, t)
+
+	P1, time1 := []float64{}, []float64{}
+	for i := uint(0); i < ns; i++ {
+		m := nd * (1 + i%3)
+		for j := uint(0); j < m; j++ {
+			P1 = append(P1, P[i*nc:(i+1)*nc]...)
+			time1 = append(time1, time[i]/float64(m))
+		}
+	}
+
+	Q1, _ = temperature.Compute(P1, time1, nil)
+	Q, _, _ = temperature.ComputeAt(P, time, Subdivide(time, config.TimeStep), nil)
+	assert.Close(Q, Q1, 1e-10, t)
+
+	Q, _, _ = temperature.ComputeAt(P, time, []float64{0, 0, time[0] / 2}, nil)
+	assert.Close(Q[:2*nc], []float64{
+		config.Ambience, config.Ambience, config.Ambience, config.Ambience,
+	}, 1e-12, t)
+
+	_, _, err = temperature.ComputeAt(P, time, []float64{time[0], 0}, nil)
+	assert.Equal(err != nil, true, t)
+
+	_, _, err = temperature.ComputeAt(P, time, []float64{2 * float64(ns) * time[0] * 3}, nil)
+	assert.Equal(err != nil, true, t)
+}
+
+func TestSubdivide(t *testing.T) {
+	assert.Equal(Subdivide([]float64{1, 0, 2}, 0), []float64{1, 1, 3}, t)
+	assert.Equal(Subdivide([]float64{1, 0, 2}, 0.5), []float64{0.5, 1, 1, 1.5, 2, 2.5, 3}, t)
+	assert.Equal(Subdivide([]float64{1}, 0.4), []float64{1.0 / 3, 2.0 / 3, 1}, t)
+}
+
+func TestFluidPeak(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		nd = 100
+		ε  = 1e-6
+	)
+
+	temperature, config, P := loadFluid(nc)
+	ns := uint(len(P) / nc)
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = 10 * config.TimeStep * float64(1+i%3)
+	}
+
+	_, S1 := temperature.Compute(P, time, nil)
+
+	Q, T, S, err := temperature.Peak(P, time, nil, ε)
+	assert.Equal(err, nil, t)
+	assert.Close(S.s, S1.s, 1e-11, t)
+
+	config.Modal = true
+	modal, _ := NewFluid(config)
+
+	_, S1 = modal.Compute(P, time, nil)
+	_, _, S, _ = modal.Peak(P, time, nil, ε)
+	assert.Close(S.s, S1.s, 1e-12, t)
+
+	moments := []float64{}
+	t0 := 0.0
+	for i := uint(0); i < ns; i++ {
+		for j := 0; j <= nd; j++ {
+			moments = append(moments, t0+time[i]*float64(j)/nd)
+		}
+		t0 += time[i]
+	}
+	Q1, _, _ := temperature.ComputeAt(P, time, moments, nil)
+
+	for k := uint(0); k < nc; k++ {
+		Tk := make([]float64, ns)
+		for i := uint(0); i < ns; i++ {
+			Tk[i] = T[i*nc+k]
+		}
+		Q2, _, _ := temperature.ComputeAt(P, time, Tk, nil)
+		for i := uint(0); i < ns; i++ {
+			assert.Close(Q2[i*nc+k], Q[i*nc+k], 1e-10, t)
+		}
+	}
+
+	t0 = 0.0
+	for i := uint(0); i < ns; i++ {
+		for k := uint(0); k < nc; k++ {
+			peak, moment := Q[i*nc+k], T[i*nc+k]
+			assert.Equal(t0 <= moment && moment <= t0+time[i], true, t)
+			for j := uint(0); j <= nd; j++ {
+				assert.Equal(Q1[(i*(nd+1)+j)*nc+k] <= peak+ε, true, t)
+			}
+		}
+		t0 += time[i]
+	}
+
+	_, _, _, err = temperature.Peak(P, time, nil, 0)
+	assert.Equal(err != nil, true, t)
+
+	_, _, _, err = temperature.Peak(P, time, nil, math.NaN())
+	assert.Equal(err != nil, true, t)
+}
+
+func TestFluidPeakInitial(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		q0 = 333.15
+	)
+
+	temperature, config, _ := loadFluid(nc)
+
+	Q0 := make([]float64, nn)
+	for i := range Q0 {
+		Q0[i] = q0
+	}
+
+	// Without power, the system cools down, and the peaks are at the start.
+	P := make([]float64, 2*nc)
+	time := []float64{config.TimeStep, config.TimeStep}
+	S0, _ := temperature.State(Q0)
+	Q, T, _, _ := temperature.Peak(P, time, S0, 1e-6)
+	assert.Close(Q[:nc], []float64{q0, q0}, 1e-10, t)
+	assert.Equal(T, []float64{0, 0, time[0], time[0]}, t)
+}
+
+func TestFluidComputeInitial(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		q0 = 333.15
+	)
+
+	temperature, config, P := loadFluid(nc)
+	ns := uint(len(P) / nc)
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep
+	}
+
+	Q0 := make([]float64, nn)
+	for i := range Q0 {
+		Q0[i] = q0
+	}
+
+	fixed, _ := loadFixed(nc)
+	S1, _ := fixed.State(Q0)
+	Q1, _ := fixed.Compute(P, S1)
+
+	S0, _ := temperature.State(Q0)
+	S, err := temperature.Initial()
+	assert.Equal(err, nil, t)
+	assert.Equal(S, S0, t) // init_temp in hotspot.config
+
+	Q, _ := temperature.Compute(P, time, S0)
+
+	assert.Close(Q, Q1, 1e-12, t)
+}
+
+func TestFluidComputeResume(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+	)
+
+	temperature, config, P := loadFluid(nc)
+	ns := uint(len(P) / nc)
+	nh := ns / 3
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep
+	}
+
+	Q, S := temperature.Compute(P[:nh*nc], time[:nh], nil)
+	S0 := append([]float64(nil), S.s...)
+	Q1, S1 := temperature.Compute(P[nh*nc:], time[nh:], S)
+	Q = append(Q, Q1...)
+	assert.Equal(S.s, S0, t)
+	assert.Equal(len(S1.s), nn, t)
+
+	Q1, S2 := temperature.Compute(P, time, nil)
+	assert.Equal(Q, Q1, t)
+	assert.Equal(S1, S2, t)
+}
+
+func TestFluidState(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.AllNodes = true
+
+	temperature, _ := NewFluid(config)
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep * float64(1+i%5)
+	}
+
+	Q, S := temperature.Compute(P, time, nil)
+	S1, _ := temperature.State(Q[(ns-1)*nn:])
+	assert.Close(S1.s, S.s, 1e-9, t)
+}
+
+func TestFluidComputeWithStatic(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature, config, P := loadFluid(nc)
+	ns := uint(len(P) / nc)
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep
+	}
+
+	leak := func(Q, P []float64) {
+		for i := range P {
+			P[i] += 0.05 * (Q[i] - 300)
+		}
+	}
+
+	fixed, P1 := loadFixed(nc)
+	Q1, _, _ := fixed.ComputeWithStatic(P1, nil, leak)
+
+	Q, _, err := temperature.ComputeWithStatic(P, time, nil, leak)
+	assert.Equal(err, nil, t)
+
+	assert.Close(Q, Q1, 1e-12, t)
+	assert.Close(P, P1, 1e-12, t)
+}
+
+func TestFluidComputeWithStaticInterpolate(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	_, config, P := loadFluid(nc)
+	config.Interpolate = true
+	ns := uint(len(P) / nc)
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep
+	}
+
+	leak := func(Q, P []float64) {
+		for i := range P {
+			P[i] += 0.05 * (Q[i] - 300)
+		}
+	}
+
+	nodal, _ := NewFluid(config)
+	P1 := append([]float64(nil), P...)
+	Q1, _, err := nodal.ComputeWithStatic(P1, time, nil, leak)
+	assert.Equal(err, nil, t)
+
+	config.Modal = true
+	modal, _ := NewFluid(config)
+	Q, _, err := modal.ComputeWithStatic(P, time, nil, leak)
+	assert.Equal(err, nil, t)
+
+	assert.Close(Q, Q1, 1e-10, t)
+	assert.Close(P, P1, 1e-10, t)
+}
+
+func TestFluidComputeInvalid(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature, _, P := loadFluid(nc)
+	ns := uint(len(P)) / nc
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = 1e-3
+	}
+
+	_, _, err := temperature.ComputeChecked(P, time[:ns-1], nil)
+	assert.Equal(err != nil, true, t)
+
+	_, _, err = temperature.ComputeChecked(P[:(ns-1)*nc], time, nil)
+	assert.Equal(err != nil, true, t)
+
+	_, _, err = temperature.ComputeChecked(P, time, &State{Power: P[:nc-1]})
+	assert.Equal(err != nil, true, t)
+
+	time[1] = -1e-3
+	_, _, err = temperature.ComputeChecked(P, time, nil)
+	assert.Equal(err != nil, true, t)
+
+	time[1] = math.NaN()
+	_, _, err = temperature.ComputeChecked(P, time, nil)
+	assert.Equal(err != nil, true, t)
+
+	_, err = temperature.State(make([]float64, 1))
+	assert.Equal(err != nil, true, t)
+}
+
+func TestFluidPeriodicSteadyState(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.AllNodes = true
+
+	temperature, _ := NewFluid(config)
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep * float64(1+i%5)
+	}
+
+	Q, err := temperature.PeriodicSteadyState(P, time)
+	assert.Equal(err, nil, t)
+
+	// The period ends in the state it starts from.
+	S0, _ := temperature.State(Q[(ns-1)*nn:])
+	Q1, _ := temperature.Compute(P, time, S0)
+	assert.Close(Q1, Q, 1e-9, t)
+
+	_, err = temperature.PeriodicSteadyState(P, make([]float64, ns))
+	assert.Equal(err != nil, true, t)
+}
+
+func TestFluidPeriodicSteadyStateInterpolate(t *testing.T) {
+	const (
+		nc = 2
+		np = 1000
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Interpolate = true
+	config.CacheSize = 5
+
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep * float64(1+i%5)
+	}
+
+	for _, modal := range []bool{false, true} {
+		config.Modal = modal
+		temperature, _ := NewFluid(config)
+
+		Q, err := temperature.PeriodicSteadyState(P, time)
+		assert.Equal(err, nil, t)
+
+		// The period is repeated until the temperature settles.
+		var Q1 []float64
+		var S *State
+		for i := 0; i < np; i++ {
+			Q1, S = temperature.Compute(P, time, S)
+		}
+		assert.Close(Q, Q1, 1e-9, t)
+	}
+}
+
+func TestFluidSteadyState(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature, _, _ := loadFluid(nc)
+	Q := temperature.SteadyState([]float64{10, 20, 15, 5})
+
+	assert.Close(Q, fixtureQSteady, 1e-10, t)
+}
+
+func TestFluidStack(t *testing.T) {
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Floorplan, config.Stack = "", findFixture("003.lcf")
+	config.Targets = []string{"bank0", "core0"}
+
+	fluid, err := NewFluid(config)
+	assert.Equal(err, nil, t)
+	assert.Equal(len(fluid.Sources), 4, t)
+
+	fixed, _ := NewFixed(config)
+
+	P := []float64{1, 2, 10, 20}
+
+	Q1 := fluid.SteadyState(P)
+	Q2 := fixed.SteadyState(P)
+	assert.Close(Q1, Q2, 1e-10, t)
+
+	// The memory die is farther from the heat sink than the processor die
+	// beneath it.
+	assert.Equal(Q1[0] > Q1[1], true, t)
+
+	P = append(P, P...)
+
+	Q1, _ = fluid.Compute(P, []float64{1e-3, 1e-3}, nil)
+	Q2, _ = fixed.Compute(P, nil)
+	assert.Close(Q1, Q2, 1e-10, t)
+}
+
 func BenchmarkFluidCompute002(b *testing.B) {
 	const (
 		nc = 2
@@ -59,7 +660,7 @@ func BenchmarkFluidCompute002(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		temperature.Compute(P, time)
+		temperature.Compute(P, time, nil)
 	}
 }
 
@@ -80,7 +681,31 @@ func BenchmarkFluidCompute032(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		temperature.Compute(P, time)
+		temperature.Compute(P, time, nil)
+	}
+}
+
+func BenchmarkFluidComputeCache032(b *testing.B) {
+	const (
+		nc = 32
+		ns = 1000
+	)
+
+	temperature, config, _ := loadFluid(nc)
+	P := random(nc*ns, 0, 20)
+
+	config.CacheSize = 4
+	temperature, _ = NewFluid(config)
+
+	time := make([]float64, ns)
+	for i := range time {
+		time[i] = config.TimeStep * float64(1+i%4)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		temperature.Compute(P, time, nil)
 	}
 }
 
@@ -0,0 +1,66 @@
+package analytic
+
+import (
+	"github.com/ready-steady/linear/matrix"
+	"github.com/turing-complete/temperature/circuit"
+)
+
+// scale multiplies the weights of links by the elements of D corresponding to
+// the thermal nodes of the links, which makes the links refer to the state of
+// the system instead of the temperature of the thermal nodes.
+func scale(links [][]circuit.Link, D []float64) [][]circuit.Link {
+	scaled := make([][]circuit.Link, len(links))
+	for k, row := range links {
+		scaled[k] = make([]circuit.Link, len(row))
+		for i, link := range row {
+			scaled[k][i] = circuit.Link{Node: link.Node, Weight: link.Weight * D[link.Node]}
+		}
+	}
+	return scaled
+}
+
+// project computes U**T * M where M is the matrix distributing the power of
+// the sources over the state of the system, which is given by links (see
+// scale).
+func project(U []float64, links [][]circuit.Link, nn uint) []float64 {
+	V := make([]float64, nn*uint(len(links)))
+	for j, row := range links {
+		for _, link := range row {
+			for i := uint(0); i < nn; i++ {
+				V[uint(j)*nn+i] += link.Weight * U[i*nn+link.Node]
+			}
+		}
+	}
+	return V
+}
+
+// observation computes the matrix mapping a state of the system in the eigenbasis
+// to the temperature of the blocks given by links (see scale) relative to the
+// ambience, that is, (U**T * M)**T where M is the matrix selecting the state
+// of the thermal nodes of the blocks.
+func observation(U []float64, links [][]circuit.Link, nn uint) []float64 {
+	no := uint(len(links))
+	Y := make([]float64, no*nn)
+	for k, row := range links {
+		for _, link := range row {
+			for i := uint(0); i < nn; i++ {
+				Y[i*no+uint(k)] += link.Weight * U[i*nn+link.Node]
+			}
+		}
+	}
+	return Y
+}
+
+// observe adds to Q the temperature of the blocks given by links (see scale)
+// relative to the ambience given a state S of the system. If the state is in
+// the eigenbasis, the links are replaced with the corresponding matrix Y (see
+// observation).
+func observe(modal bool, links [][]circuit.Link, Y, S, Q []float64) {
+	if modal {
+		matrix.MultiplyAdd(Y, S, Q, Q, uint(len(Q)), uint(len(S)), 1)
+		return
+	}
+	for k := range links {
+		Q[k] += circuit.Measure(links[k], S)
+	}
+}
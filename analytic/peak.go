@@ -0,0 +1,157 @@
+package analytic
+
+import (
+	"math"
+
+	"github.com/ready-steady/linear/matrix"
+)
+
+// peaks calculates the peak temperature of the targets within each sample of a
+// power profile along with the time moments of the peaks. The state S of the
+// system is given in the eigenbasis and is overwritten with the final state.
+// If P0 is not nil, power is interpolated between samples (see Interpolate in
+// Config), and P0 is the power preceding the first sample.
+//
+// Within a sample, the temperature of a target is c + β τ + Σ g_j exp(λ_j τ)
+// where τ is the time elapsed since the beginning of the sample, and β is zero
+// unless power is interpolated between samples (see Interpolate in Config).
+// The maximum of such a function is located by bisecting the sample while
+// keeping the parts that might contain a zero of the derivative exceeding the
+// best value found so far by more than ε. The curvature of the function is
+// bounded on each part, which makes the error in the peak temperature at most
+// ε.
+func peaks(Λ, V, Y []float64, P, P0, ΔT, S []float64, nc, nn, no uint,
+	qamb, ε float64) ([]float64, []float64) {
+
+	ns := uint(len(ΔT))
+
+	Q := make([]float64, no*ns)
+	T := make([]float64, no*ns)
+
+	W := make([]float64, nn)
+	Wp := make([]float64, nn)
+	A := make([]float64, nn)
+	B := make([]float64, nn)
+	G := make([]float64, nn)
+
+	interpolate := P0 != nil
+	if interpolate {
+		matrix.Multiply(V, P0, Wp, nn, nc, 1)
+	}
+
+	t0 := 0.0
+	for i := uint(0); i < ns; i++ {
+		Δt := ΔT[i]
+
+		matrix.Multiply(V, P[i*nc:(i+1)*nc], W, nn, nc, 1)
+		if !interpolate {
+			copy(Wp, W)
+		}
+
+		// The state within the sample is exp(λ τ) (S - A) + A + B τ where
+		// the power changes from Wp to W in the eigenbasis.
+		for j := uint(0); j < nn; j++ {
+			d := 0.0
+			if Δt > 0 {
+				d = (W[j] - Wp[j]) / Δt
+			}
+			A[j] = -Wp[j]/Λ[j] - d/(Λ[j]*Λ[j])
+			B[j] = -d / Λ[j]
+		}
+
+		for k := uint(0); k < no; k++ {
+			c, β := 0.0, 0.0
+			for j := uint(0); j < nn; j++ {
+				G[j] = Y[j*no+k] * (S[j] - A[j])
+				c += Y[j*no+k] * A[j]
+				β += Y[j*no+k] * B[j]
+			}
+			q, τ := maximize(G, Λ, β, Δt, ε)
+			Q[i*no+k] = qamb + c + q
+			T[i*no+k] = t0 + τ
+		}
+
+		for j := uint(0); j < nn; j++ {
+			e := math.Exp(Δt * Λ[j])
+			S[j] = e*S[j] + (e-1.0)/Λ[j]*Wp[j]
+			if interpolate {
+				S[j] += Δt * phi2(Δt*Λ[j]) * (W[j] - Wp[j])
+			}
+		}
+
+		W, Wp = Wp, W
+		t0 += Δt
+	}
+
+	return Q, T
+}
+
+// maximize finds the maximum of β τ + Σ g_j exp(λ_j τ) over τ in [0, Δt] with
+// an error of at most ε and returns the maximum along with its location.
+func maximize(G, Λ []float64, β, Δt, ε float64) (float64, float64) {
+	type part struct {
+		a, b   float64
+		qa, qb float64
+		da, db float64
+	}
+
+	evaluate := func(τ float64) (float64, float64) {
+		q, d := β*τ, β
+		for j := range G {
+			term := G[j] * math.Exp(Λ[j]*τ)
+			q += term
+			d += term * Λ[j]
+		}
+		return q, d
+	}
+
+	// The bound on the absolute value of the second derivative on [a, b].
+	curvature := func(a, b float64) float64 {
+		sum := 0.0
+		for j := range G {
+			sum += math.Abs(G[j]) * Λ[j] * Λ[j] * math.Max(math.Exp(Λ[j]*a), math.Exp(Λ[j]*b))
+		}
+		return sum
+	}
+
+	qa, da := evaluate(0)
+	qb, db := evaluate(Δt)
+
+	best, location := qa, 0.0
+	if qb > best {
+		best, location = qb, Δt
+	}
+
+	parts := []part{part{0, Δt, qa, qb, da, db}}
+	for len(parts) > 0 {
+		p := parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+
+		h := p.b - p.a
+		m := curvature(p.a, p.b)
+
+		// Any function whose second derivative is bounded by m deviates from
+		// the linear interpolation of its ends by at most m h^2 / 8.
+		if math.Max(p.qa, p.qb)+m*h*h/8 <= best+ε {
+			continue
+		}
+		// The derivative cannot vanish if its values at the ends have the
+		// same sign and are too large to be cancelled within the part.
+		if p.da*p.db > 0 && math.Abs(p.da)+math.Abs(p.db) > m*h {
+			continue
+		}
+
+		τ := p.a + h/2
+		if τ <= p.a || τ >= p.b {
+			continue
+		}
+		q, d := evaluate(τ)
+		if q > best {
+			best, location = q, τ
+		}
+
+		parts = append(parts, part{p.a, τ, p.qa, q, p.da, d}, part{τ, p.b, q, p.qb, d, p.db})
+	}
+
+	return best, location
+}
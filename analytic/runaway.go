@@ -0,0 +1,9 @@
+package analytic
+
+import (
+	"github.com/turing-complete/temperature/internal/runaway"
+)
+
+// RunawayError is an error signaling thermal runaway (see Ceiling in Config
+// and ComputeWithStatic in the integrators).
+type RunawayError = runaway.Error
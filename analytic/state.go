@@ -0,0 +1,141 @@
+package analytic
+
+import (
+	"github.com/ready-steady/linear/matrix"
+	"github.com/turing-complete/temperature/internal/check"
+)
+
+// State is a state of a thermal system at a particular time moment.
+//
+// A state is either calculated from the temperature of the thermal nodes (see
+// State in the integrators) or returned by a computation as the state of the
+// system at the end of the computation. In both cases, it can be passed to a
+// subsequent computation as the initial state, which resumes the computation.
+// A nil state corresponds to the ambient temperature. The integrators never
+// modify states passed to them.
+//
+// A state keeps track of the basis it is expressed in, which is either the
+// basis of the thermal nodes or the eigenbasis of the system (see Modal in
+// Config), and it is converted when passed to an integrator working in the
+// other basis.
+type State struct {
+	// The power of the sources at the time moment of the state, which is the
+	// last power sample of the computation that has led to the state. When
+	// power is interpolated between samples (see Interpolate in Config), it is
+	// the power at the beginning of the first time step of a subsequent
+	// computation. If nil, the first power sample of the computation is taken
+	// instead.
+	Power []float64
+
+	s []float64
+
+	// The eigenvectors of the system if s is expressed in the eigenbasis;
+	// otherwise, u is nil.
+	u []float64
+}
+
+func newState(s, u, power []float64) *State {
+	return &State{
+		Power: append([]float64(nil), power...),
+
+		s: append([]float64(nil), s...),
+		u: u,
+	}
+}
+
+// temperatureState returns the state corresponding to the temperature Q of the
+// thermal nodes in the eigenbasis given by U or in the basis of the thermal
+// nodes if U is nil.
+func temperatureState(Q, D, U []float64, qamb float64, nn uint) *State {
+	S := make([]float64, nn)
+	for i := uint(0); i < nn; i++ {
+		S[i] = (Q[i] - qamb) / D[i]
+	}
+	if U == nil {
+		return &State{s: S}
+	}
+
+	R := make([]float64, nn)
+	matrix.Multiply(S, U, R, 1, nn, nn)
+
+	return &State{s: R, u: U}
+}
+
+// check validates the state vector and the power of the state given the
+// number of sources and thermal nodes.
+func (self *State) check(nc, nn uint) error {
+	if self == nil {
+		return nil
+	}
+	if err := check.State(self.s, nn); err != nil {
+		return err
+	}
+	return check.Sample(self.Power, nc)
+}
+
+// power returns the power sample preceding a power profile P, which is the
+// power of the state if known and the first sample of P otherwise. The result
+// is nil if neither is available.
+func (self *State) power(P []float64, nc uint) []float64 {
+	if self != nil && self.Power != nil {
+		return self.Power
+	}
+	if uint(len(P)) < nc {
+		return nil
+	}
+	return P[:nc]
+}
+
+// last returns the last sample of a power profile P or the power of the
+// initial state S0 if P is empty.
+func last(P []float64, nc uint, S0 *State) []float64 {
+	if ns := uint(len(P)) / nc; ns > 0 {
+		return P[(ns-1)*nc : ns*nc]
+	}
+	if S0 == nil {
+		return nil
+	}
+	return S0.Power
+}
+
+// in returns the state vector of the state expressed in the eigenbasis given
+// by U or in the basis of the thermal nodes if U is nil. The result is nil if
+// the state is nil, and it should not be modified.
+func (self *State) in(U []float64, nn uint) []float64 {
+	if self == nil || self.s == nil {
+		return nil
+	}
+	if same(self.u, U) {
+		return self.s
+	}
+
+	S := self.s
+	if self.u != nil {
+		R := make([]float64, nn)
+		matrix.Multiply(self.u, S, R, nn, nn, 1)
+		S = R
+	}
+	if U != nil {
+		R := make([]float64, nn)
+		matrix.Multiply(S, U, R, 1, nn, nn)
+		S = R
+	}
+
+	return S
+}
+
+// to returns the state expressed in the eigenbasis given by U or in the basis
+// of the thermal nodes if U is nil.
+func (self *State) to(U []float64, nn uint) *State {
+	if self == nil || same(self.u, U) {
+		return self
+	}
+	return &State{Power: self.Power, s: self.in(U, nn), u: U}
+}
+
+func same(U1, U2 []float64) bool {
+	if len(U1) != len(U2) {
+		return false
+	}
+	return len(U1) == 0 || &U1[0] == &U2[0]
+}
@@ -0,0 +1,90 @@
+package analytic
+
+import (
+	"github.com/ready-steady/linear/matrix"
+)
+
+// Stepper is an integrator of a thermal system advancing one sample at a time.
+// It is built on top of Fixed and performs no allocation at each step.
+type Stepper struct {
+	fixed *Fixed
+
+	// The current state of the system.
+	s []float64
+
+	// The previous power sample, which is used when power is interpolated
+	// between samples (see Interpolate in Config). It is unknown until the
+	// first step unless given by the initial state.
+	power  []float64
+	primed bool
+
+	temp []float64
+}
+
+// NewStepper returns a new stepper. The initial state of the system is
+// specified by S0 (see State); if S0 is nil, the system starts at the ambient
+// temperature. The power of the state, if any, is taken as the previous power
+// sample.
+func NewStepper(fixed *Fixed, S0 *State) *Stepper {
+	nn := fixed.nn
+
+	stepper := &Stepper{
+		fixed: fixed,
+
+		s: make([]float64, nn),
+
+		power: make([]float64, fixed.nc),
+
+		temp: make([]float64, nn),
+	}
+	copy(stepper.s, S0.in(fixed.basis(), nn))
+	if S0 != nil && S0.Power != nil {
+		copy(stepper.power, S0.Power)
+		stepper.primed = true
+	}
+
+	return stepper
+}
+
+// State returns the current state of the system along with the last power
+// sample, which can be used to resume the computation with Fixed or with
+// another stepper.
+func (self *Stepper) State() *State {
+	var power []float64
+	if self.primed {
+		power = self.power
+	}
+	return newState(self.s, self.fixed.basis(), power)
+}
+
+// Step advances the system by one time step (see TimeStep in Config).
+//
+// The power dissipation during the time step is specified by a vector P. If
+// power is interpolated between samples (see Interpolate in Config), P is the
+// power at the end of the time step. The temperature at the end of the time
+// step is written to a vector Q.
+func (self *Stepper) Step(P, Q []float64) {
+	fixed := self.fixed
+	nc, nn, no := fixed.nc, fixed.nn, fixed.no
+
+	F, G, out, Y, qamb := fixed.F, fixed.G, fixed.out, fixed.Y, fixed.qamb
+	S1, S2 := self.temp, self.s
+
+	matrix.Multiply(F, P, S1, nn, nc, 1)
+	if G != nil {
+		Pp := self.power
+		if !self.primed {
+			Pp = P[:nc]
+		}
+		matrix.MultiplyAdd(G, Pp, S1, S1, nn, nc, 1)
+	}
+	copy(self.power, P[:nc])
+	self.primed = true
+	fixed.propagate(S2, S1)
+	for k := uint(0); k < no; k++ {
+		Q[k] = qamb
+	}
+	observe(fixed.modal, out, Y, S1, Q[:no])
+
+	self.s, self.temp = S1, S2
+}
@@ -0,0 +1,158 @@
+package analytic
+
+import (
+	"testing"
+
+	"github.com/ready-steady/assert"
+	"github.com/ready-steady/fixture"
+)
+
+func TestStepperStep(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature, P := loadFixed(nc)
+	ns := uint(len(P)) / nc
+
+	stepper := NewStepper(temperature, nil)
+
+	Q := make([]float64, nc*ns)
+	for i := uint(0); i < ns; i++ {
+		stepper.Step(P[i*nc:(i+1)*nc], Q[i*nc:(i+1)*nc])
+	}
+
+	Q1, _ := temperature.Compute(P, nil)
+	assert.Equal(Q, Q1, t)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Modal = true
+
+	temperature, _ = NewFixed(config)
+	stepper = NewStepper(temperature, nil)
+
+	for i := uint(0); i < ns; i++ {
+		stepper.Step(P[i*nc:(i+1)*nc], Q[i*nc:(i+1)*nc])
+	}
+
+	Q1, _ = temperature.Compute(P, nil)
+	assert.Equal(Q, Q1, t)
+}
+
+func TestStepperStepAllocation(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	for _, modal := range []bool{false, true} {
+		config := &Config{}
+		fixture.Load(findFixture("002.json"), config)
+		config.Modal = modal
+
+		temperature, _ := NewFixed(config)
+		stepper := NewStepper(temperature, nil)
+		P := fixtureP[:nc]
+		Q := make([]float64, nc)
+
+		allocations := testing.AllocsPerRun(10, func() {
+			stepper.Step(P, Q)
+		})
+		assert.Equal(allocations, 0.0, t)
+	}
+}
+
+func TestStepperStepInterpolate(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Interpolate = true
+
+	temperature, _ := NewFixed(config)
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+
+	stepper := NewStepper(temperature, nil)
+
+	Q := make([]float64, nc*ns)
+	for i := uint(0); i < ns; i++ {
+		stepper.Step(P[i*nc:(i+1)*nc], Q[i*nc:(i+1)*nc])
+	}
+
+	Q1, _ := temperature.Compute(P, nil)
+	assert.Close(Q, Q1, 1e-10, t)
+}
+
+func TestStepperState(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature, P := loadFixed(nc)
+	ns := uint(len(P)) / nc
+	nh := ns / 2
+
+	stepper := NewStepper(temperature, nil)
+
+	Q := make([]float64, nc)
+	for i := uint(0); i < nh; i++ {
+		stepper.Step(P[i*nc:(i+1)*nc], Q)
+	}
+
+	Q1, S := temperature.Compute(P[:nh*nc], nil)
+	assert.Equal(Q, Q1[(nh-1)*nc:], t)
+	assert.Equal(stepper.State(), S, t)
+
+	Q1, _ = temperature.Compute(P[nh*nc:], stepper.State())
+	Q2, _ := temperature.Compute(P, nil)
+	assert.Equal(Q1, Q2[nh*nc:], t)
+}
+
+func TestStepperStateInterpolate(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Interpolate = true
+
+	temperature, _ := NewFixed(config)
+	P := append([]float64(nil), fixtureP...)
+	ns := uint(len(P)) / nc
+	nh := ns / 2
+
+	Q1, S := temperature.Compute(P[:nh*nc], nil)
+	assert.Equal(S.Power, P[(nh-1)*nc:nh*nc], t)
+
+	stepper := NewStepper(temperature, S)
+
+	Q := make([]float64, nc*(ns-nh))
+	for i := nh; i < ns; i++ {
+		stepper.Step(P[i*nc:(i+1)*nc], Q[(i-nh)*nc:(i-nh+1)*nc])
+	}
+
+	Q2, _ := temperature.Compute(P, nil)
+	assert.Close(append(Q1, Q...), Q2, 1e-10, t)
+	assert.Equal(stepper.State().Power, P[(ns-1)*nc:], t)
+}
+
+func BenchmarkStepperStep032(b *testing.B) {
+	const (
+		nc = 32
+	)
+
+	temperature, _ := loadFixed(nc)
+	stepper := NewStepper(temperature, nil)
+	P := random(nc, 0, 20)
+	Q := make([]float64, nc)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		stepper.Step(P, Q)
+	}
+}
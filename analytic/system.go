@@ -0,0 +1,113 @@
+package analytic
+
+import (
+	"math"
+
+	"github.com/ready-steady/linear/decomposition"
+	"github.com/turing-complete/temperature/circuit"
+	"github.com/turing-complete/temperature/internal/network"
+)
+
+// system is a thermal system prepared for analysis. The eigenvalues Λ and the
+// eigenvectors U are those of A = -D * G * D where G is the conductance matrix,
+// and D = C**(-1/2) where C is the capacitance matrix. The links are scaled by
+// D (see scale).
+type system struct {
+	nc uint
+	nn uint
+	no uint
+
+	in    [][]circuit.Link
+	out   [][]circuit.Link
+	probe [][]circuit.Link
+
+	D []float64
+	Λ []float64
+	U []float64
+
+	// The steady-state temperature rise of the sources per unit power of the
+	// sources (see resistance).
+	R []float64
+
+	// The initial temperature of the thermal nodes (see Initial in
+	// circuit.Model).
+	initial func() ([]float64, error)
+
+	sources []circuit.Block
+	targets []circuit.Block
+	nodes   []circuit.Node
+}
+
+// decompose builds the thermal system given by a configuration and computes
+// the eigendecomposition of the system.
+func decompose(config *Config) (*system, error) {
+	model, err := network.New(&config.Config, config.Ambience, config.Sources,
+		config.Targets, config.AllNodes)
+	if err != nil {
+		return nil, err
+	}
+	in, out, probe := model.In, model.Out, model.Probe
+
+	nodes := model.Nodes
+	nn := uint(len(nodes))
+
+	A := model.G // Reuse model.G to store A.
+	D := model.C // Reuse model.C to store D.
+	for i := uint(0); i < nn; i++ {
+		D[i] = math.Sqrt(1.0 / model.C[i])
+	}
+	for i := uint(0); i < nn; i++ {
+		for j := uint(0); j < nn; j++ {
+			A[j*nn+i] = -D[i] * D[j] * A[j*nn+i]
+		}
+	}
+
+	U := A // Reuse A (which is model.G) to store U.
+	Λ := make([]float64, nn)
+	if err := decomposition.SymmetricEigen(A, U, Λ, nn); err != nil {
+		return nil, err
+	}
+
+	in, out, probe = scale(in, D), scale(out, D), scale(probe, D)
+	nc := uint(len(in))
+
+	return &system{
+		nc: nc,
+		nn: nn,
+		no: uint(len(out)),
+
+		in:    in,
+		out:   out,
+		probe: probe,
+
+		D: D,
+		Λ: Λ,
+		U: U,
+
+		R: resistance(Λ, project(U, in, nn), observation(U, probe, nn), nc, nn),
+
+		initial: model.Initial,
+
+		sources: model.Sources,
+		targets: model.Targets,
+		nodes:   nodes,
+	}, nil
+}
+
+// resistance computes the matrix whose column j is the steady-state
+// temperature of the sources relative to the ambience when source j
+// dissipates unit power. The eigenvalues Λ, the projection V (see project),
+// and the observation Z of the sources (see observation) are those of the
+// system.
+func resistance(Λ, V, Z []float64, nc, nn uint) []float64 {
+	R := make([]float64, nc*nc)
+	for j := uint(0); j < nc; j++ {
+		for i := uint(0); i < nn; i++ {
+			v := -V[j*nn+i] / Λ[i]
+			for k := uint(0); k < nc; k++ {
+				R[j*nc+k] += Z[i*nc+k] * v
+			}
+		}
+	}
+	return R
+}
@@ -0,0 +1,183 @@
+package circuit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Config is a configuration of a thermal RC circuit.
+type Config struct {
+	// The path to the floorplan (see LoadFloorplan).
+	Floorplan string
+
+	// The path to the layer configuration of a stacked die (see LoadLayers).
+	// If not empty, the circuit is built from the layers (see BuildStack),
+	// and Floorplan is ignored.
+	Stack string
+
+	// The path to the configuration of HotSpot (see LoadSpecs). If empty, the
+	// default parameters are used.
+	Configuration string
+
+	// The parameters overwriting those of Configuration (see ParseParameters).
+	Parameters string
+
+	// The floorplan given in memory. If not empty, the circuit is built from
+	// Blocks and Specs, and Floorplan, Configuration, and Parameters are
+	// ignored.
+	Blocks []Block
+
+	// The layers of a stacked die given in memory. If not empty, the circuit
+	// is built from Layers and Specs (see BuildStack), and the other fields
+	// are ignored.
+	Layers []Layer
+
+	// The parameters given in memory, which are used together with Blocks or
+	// Layers. If nil, the default parameters are used.
+	Specs *Specs
+}
+
+// New constructs the thermal RC circuit specified by a configuration.
+func New(config *Config) (*Model, error) {
+	layers, blocks, specs := config.Layers, config.Blocks, config.Specs
+	if len(layers) == 0 && len(blocks) == 0 {
+		var err error
+		if len(config.Stack) > 0 {
+			if layers, err = LoadLayers(config.Stack); err != nil {
+				return nil, err
+			}
+			specs, err = loadSpecs(config.Configuration, config.Parameters)
+		} else {
+			blocks, specs, err = Load(config.Floorplan, config.Configuration, config.Parameters)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if specs == nil {
+		specs = DefaultSpecs()
+	}
+
+	if len(layers) > 0 {
+		return BuildStack(layers, specs)
+	}
+	return Build(blocks, specs)
+}
+
+// Load reads a floorplan (see LoadFloorplan) and the parameters of a thermal
+// model (see LoadSpecs) and overwrites the parameters with those given in the
+// format of the command line of HotSpot (see ParseParameters). The path to the
+// configuration and the parameters can be empty, in which case the default
+// values are used.
+func Load(floorplan, configuration, parameters string) ([]Block, *Specs, error) {
+	blocks, err := LoadFloorplan(floorplan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	specs, err := loadSpecs(configuration, parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blocks, specs, nil
+}
+
+// LoadConfiguration reads the parameters of a thermal model from a file in the
+// format of HotSpot (hotspot.config). Each line of the file contains a
+// parameter name preceded by a dash and followed by a value; the text after a
+// hash sign is ignored.
+func LoadConfiguration(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseConfiguration(file, path)
+}
+
+// ParseParameters parses the parameters of a thermal model given in the format
+// of the command line of HotSpot, that is, as a sequence of parameter names
+// preceded by dashes and followed by values.
+func ParseParameters(line string) (map[string]string, error) {
+	fields := strings.Fields(line)
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("the parameter %q should have a value", fields[len(fields)-1])
+	}
+
+	parameters := make(map[string]string, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		if err := insert(parameters, fields[i], fields[i+1]); err != nil {
+			return nil, err
+		}
+	}
+
+	return parameters, nil
+}
+
+func loadSpecs(configuration, parameters string) (*Specs, error) {
+	specs := DefaultSpecs()
+	if len(configuration) > 0 {
+		var err error
+		if specs, err = LoadSpecs(configuration); err != nil {
+			return nil, err
+		}
+	}
+
+	overrides, err := ParseParameters(parameters)
+	if err != nil {
+		return nil, err
+	}
+	if err := specs.Update(overrides); err != nil {
+		return nil, err
+	}
+	if err := specs.check(); err != nil {
+		return nil, err
+	}
+
+	return specs, nil
+}
+
+func parseConfiguration(reader io.Reader, path string) (map[string]string, error) {
+	parameters := make(map[string]string)
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a parameter name followed by a value",
+				path, line)
+		}
+		if err := insert(parameters, fields[0], fields[1]); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return parameters, nil
+}
+
+func insert(parameters map[string]string, name, value string) error {
+	if len(name) < 2 || name[0] != '-' {
+		return fmt.Errorf("the parameter %q should start with a dash", name)
+	}
+	name = name[1:]
+	if _, ok := parameters[name]; ok {
+		return fmt.Errorf("the parameter %q is specified more than once", name)
+	}
+	parameters[name] = value
+	return nil
+}
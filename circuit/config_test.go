@@ -0,0 +1,114 @@
+package circuit
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestLoadConfiguration(t *testing.T) {
+	parameters, err := LoadConfiguration(findFixture("hotspot.config"))
+
+	assert.Equal(err, nil, t)
+	assert.Equal(parameters["t_chip"], "0.00015", t)
+	assert.Equal(parameters["model_type"], "block", t)
+	assert.Equal(parameters["init_file"], "(null)", t)
+}
+
+func TestLoadConfigurationMissing(t *testing.T) {
+	_, err := LoadConfiguration(findFixture("missing.config"))
+
+	assert.Equal(err != nil, true, t)
+}
+
+func TestParseConfiguration(t *testing.T) {
+	parameters, err := parseConfiguration(strings.NewReader(`
+		# comment
+		-t_chip 0.00015 # trailing comment
+		-model_type block
+	`), "")
+	assert.Equal(err, nil, t)
+	assert.Equal(parameters, map[string]string{"t_chip": "0.00015", "model_type": "block"}, t)
+
+	_, err = parseConfiguration(strings.NewReader("-t_chip"), "")
+	assert.Equal(err != nil, true, t)
+
+	_, err = parseConfiguration(strings.NewReader("t_chip 0.00015"), "")
+	assert.Equal(err != nil, true, t)
+
+	_, err = parseConfiguration(strings.NewReader("-t_chip 0.00015\n-t_chip 0.0001"), "")
+	assert.Equal(err != nil, true, t)
+}
+
+func TestParseParameters(t *testing.T) {
+	parameters, err := ParseParameters("-t_chip 0.00015  -k_chip 100.0")
+	assert.Equal(err, nil, t)
+	assert.Equal(parameters, map[string]string{"t_chip": "0.00015", "k_chip": "100.0"}, t)
+
+	parameters, err = ParseParameters("")
+	assert.Equal(err, nil, t)
+	assert.Equal(len(parameters), 0, t)
+
+	_, err = ParseParameters("-t_chip 0.00015 -k_chip")
+	assert.Equal(err != nil, true, t)
+}
+
+func TestLoad(t *testing.T) {
+	blocks, specs, err := Load(findFixture("002.flp"), findFixture("hotspot.config"),
+		"-t_chip 0.0001 -block_omit_lateral 1")
+
+	assert.Equal(err, nil, t)
+	assert.Equal(len(blocks), 2, t)
+
+	expected := DefaultSpecs()
+	expected.ChipThickness = 0.0001
+	expected.OmitLateral = true
+	assert.Equal(specs, expected, t)
+
+	_, specs, err = Load(findFixture("002.flp"), "", "")
+	assert.Equal(err, nil, t)
+	assert.Equal(specs, DefaultSpecs(), t)
+
+	_, _, err = Load(findFixture("002.flp"), "", "-k_chip high")
+	assert.Equal(err != nil, true, t)
+
+	_, _, err = Load(findFixture("002.flp"), "", "-k_chip -1")
+	assert.Equal(err != nil, true, t)
+
+	_, specs, err = Load(findFixture("002.flp"), "",
+		"-grid_rows 16 -grid_cols 32 -grid_map_mode avg")
+	assert.Equal(err, nil, t)
+	assert.Equal(specs, DefaultSpecs(), t)
+
+	_, _, err = Load(findFixture("002.flp"), "", "-model_type grid -grid_rows 16 -grid_cols 32")
+	assert.Equal(err != nil, true, t)
+
+	_, specs, err = Load(findFixture("002.flp"), "", "-model_secondary 1 -n_c4 800 -s_pcb 0.2")
+	assert.Equal(err, nil, t)
+	assert.Equal(specs.Secondary, true, t)
+	assert.Equal(specs.BumpCount, uint(800), t)
+	assert.Equal(specs.BoardSide, 0.2, t)
+
+	_, specs, err = Load(findFixture("002.flp"), "", "-init_temp 300 -init_file (null)")
+	assert.Equal(err, nil, t)
+	assert.Equal(specs.InitialTemperature, 300.0, t)
+	assert.Equal(specs.InitialFile, "", t)
+
+	_, specs, err = Load(findFixture("002.flp"), "", "-init_file missing.init")
+	assert.Equal(err, nil, t)
+	assert.Equal(specs.InitialFile, "missing.init", t)
+}
+
+func TestLoadSpecsInitialFile(t *testing.T) {
+	name := path.Join(os.TempDir(), "circuit_initial.config")
+	defer os.Remove(name)
+
+	assert.Equal(ioutil.WriteFile(name, []byte("-init_file missing.init"), 0644), nil, t)
+	specs, err := LoadSpecs(name)
+	assert.Equal(err, nil, t)
+	assert.Equal(specs.InitialFile, path.Join(os.TempDir(), "missing.init"), t)
+}
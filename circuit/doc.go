@@ -0,0 +1,36 @@
+// Package circuit provides a description of the thermal RC circuit of a
+// multiprocessor system.
+//
+// The circuit is built according to the block model of HotSpot. Each block of
+// the floorplan gives rise to four thermal nodes: one in the die, one in the
+// thermal interface material, one in the heat spreader, and one in the heat
+// sink. In addition, there are twelve peripheral nodes: four in the heat
+// spreader and eight in the heat sink.
+//
+// Alternatively, the die is divided into a grid of cells (see Rows and Columns
+// in Specs), and the circuit is assembled in the same way as in the block model
+// with the cells in place of the blocks, so that each cell gives rise to four
+// thermal nodes. The power of the blocks is then spread over the cells, and
+// the temperature of the blocks is gathered from the cells (see Link). Note
+// that this is a refinement of the block model and not the grid model of
+// HotSpot, which discretizes the layers of the package differently and,
+// therefore, gives different results. For this reason, the grid model of
+// HotSpot (model_type grid) is rejected, and the refinement is not set by the
+// configuration of HotSpot.
+//
+// Instead of a single die with the thermal interface material, the circuit can
+// contain a stack of layers, each with its own floorplan, given in the format
+// of the layer configuration files of HotSpot (see Layer and BuildStack). Each
+// layer then gives rise to one thermal node per block or cell.
+//
+// In both models, the circuit can also include the secondary heat path of
+// HotSpot (see Secondary in Specs), which leads from the die through the metal
+// layers, the C4 pads, the package substrate, the solder balls, and the
+// printed circuit board to the ambience. Each block or cell then gives rise to
+// five more thermal nodes, and there are sixteen more peripheral nodes, all of
+// which follow the nodes of the primary heat path.
+//
+// The circuit is constructed in pure Go, without HotSpot itself, either by New
+// from the files of HotSpot or by Build from a floorplan given in memory. In
+// the block model, the result coincides with the one of HotSpot.
+package circuit
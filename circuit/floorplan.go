@@ -0,0 +1,117 @@
+package circuit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Block is a rectangular functional unit of a floorplan.
+type Block struct {
+	Name string
+
+	Width  float64 // in meters
+	Height float64 // in meters
+	Left   float64 // in meters
+	Bottom float64 // in meters
+}
+
+// LoadFloorplan reads a floorplan from a file in the format of HotSpot (.flp).
+// Blank lines and comments are skipped, and the optional columns following the
+// coordinates of a block are ignored.
+func LoadFloorplan(path string) ([]Block, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blocks := []Block{}
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("%s:%d: expected a name followed by four numbers", path, line)
+		}
+
+		values := make([]float64, 4)
+		for i := range values {
+			if values[i], err = strconv.ParseFloat(fields[i+1], 64); err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+			}
+			if math.IsNaN(values[i]) || math.IsInf(values[i], 0) {
+				return nil, fmt.Errorf("%s:%d: the dimensions should be finite", path, line)
+			}
+		}
+		if values[0] <= 0 || values[1] <= 0 {
+			return nil, fmt.Errorf("%s:%d: the width and height should be positive", path, line)
+		}
+		if seen[fields[0]] {
+			return nil, fmt.Errorf("%s:%d: the block %q is defined more than once",
+				path, line, fields[0])
+		}
+		seen[fields[0]] = true
+
+		blocks = append(blocks, Block{
+			Name: fields[0],
+
+			Width:  values[0],
+			Height: values[1],
+			Left:   values[2],
+			Bottom: values[3],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(blocks) == 0 {
+		return nil, errors.New("the floorplan should contain at least one block")
+	}
+
+	return blocks, nil
+}
+
+// Select returns the blocks with particular names along with their indices in
+// the floorplan. If the list of names is empty, all the blocks are returned.
+func Select(blocks []Block, names []string) ([]Block, []uint, error) {
+	if len(names) == 0 {
+		indices := make([]uint, len(blocks))
+		for i := range indices {
+			indices[i] = uint(i)
+		}
+		return blocks, indices, nil
+	}
+
+	mapping := make(map[string]uint, len(blocks))
+	for i, block := range blocks {
+		mapping[block.Name] = uint(i)
+	}
+
+	selection := make([]Block, len(names))
+	indices := make([]uint, len(names))
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		j, ok := mapping[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("the block %q is not found in the floorplan", name)
+		}
+		if seen[name] {
+			return nil, nil, fmt.Errorf("the block %q is selected more than once", name)
+		}
+		seen[name] = true
+		selection[i] = blocks[j]
+		indices[i] = j
+	}
+
+	return selection, indices, nil
+}
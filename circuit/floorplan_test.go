@@ -0,0 +1,79 @@
+package circuit
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestLoadFloorplan(t *testing.T) {
+	blocks, err := LoadFloorplan(findFixture("002.flp"))
+
+	assert.Equal(err, nil, t)
+	assert.Equal(blocks, []Block{
+		Block{Name: "core0", Width: 0.002, Height: 0.002, Left: 0.000, Bottom: 0.000},
+		Block{Name: "core1", Width: 0.002, Height: 0.002, Left: 0.002, Bottom: 0.000},
+	}, t)
+}
+
+func TestLoadFloorplanExtended(t *testing.T) {
+	blocks, err := LoadFloorplan(findFixture("003.flp"))
+
+	assert.Equal(err, nil, t)
+	assert.Equal(blocks, []Block{
+		Block{Name: "core0", Width: 0.002, Height: 0.001, Left: 0.000, Bottom: 0.000},
+		Block{Name: "core1", Width: 0.002, Height: 0.001, Left: 0.002, Bottom: 0.000},
+		Block{Name: "cache", Width: 0.004, Height: 0.002, Left: 0.000, Bottom: 0.001},
+	}, t)
+}
+
+func TestLoadFloorplanMissing(t *testing.T) {
+	_, err := LoadFloorplan(findFixture("missing.flp"))
+
+	assert.Equal(err != nil, true, t)
+}
+
+func TestLoadFloorplanInvalid(t *testing.T) {
+	name := path.Join(os.TempDir(), "circuit_invalid.flp")
+	defer os.Remove(name)
+
+	cases := []string{
+		"core0 0.002",
+		"core0 0.002 0.002 0 x",
+		"core0 0.002 0 0 0",
+		"core0 0.002 0.002 0 NaN",
+		"core0 0.002 0.002 0 0\ncore0 0.002 0.002 0.002 0",
+	}
+	for _, content := range cases {
+		assert.Equal(ioutil.WriteFile(name, []byte(content), 0644), nil, t)
+		_, err := LoadFloorplan(name)
+		assert.Equal(err != nil, true, t)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	blocks := []Block{Block{Name: "core0"}, Block{Name: "cache"}, Block{Name: "core1"}}
+
+	selection, indices, err := Select(blocks, nil)
+	assert.Equal(err, nil, t)
+	assert.Equal(selection, blocks, t)
+	assert.Equal(indices, []uint{0, 1, 2}, t)
+
+	selection, indices, err = Select(blocks, []string{"core1", "core0"})
+	assert.Equal(err, nil, t)
+	assert.Equal(selection, []Block{blocks[2], blocks[0]}, t)
+	assert.Equal(indices, []uint{2, 0}, t)
+
+	_, _, err = Select(blocks, []string{"core2"})
+	assert.Equal(err != nil, true, t)
+
+	_, _, err = Select(blocks, []string{"core0", "core0"})
+	assert.Equal(err != nil, true, t)
+}
+
+func findFixture(name string) string {
+	return path.Join("fixtures", name)
+}
@@ -0,0 +1,93 @@
+package circuit
+
+import (
+	"fmt"
+	"math"
+)
+
+// divide splits a rectangle into a grid of cells, which are ordered row by row
+// starting from the bottom-left corner.
+func divide(left, bottom, right, top float64, nr, nc uint) []Block {
+	w, h := (right-left)/float64(nc), (top-bottom)/float64(nr)
+
+	cells := make([]Block, nr*nc)
+	for i := uint(0); i < nr; i++ {
+		for j := uint(0); j < nc; j++ {
+			cells[i*nc+j] = Block{
+				Name: fmt.Sprintf("cell_%d_%d", i, j),
+
+				Width:  w,
+				Height: h,
+				Left:   left + float64(j)*w,
+				Bottom: bottom + float64(i)*h,
+			}
+		}
+	}
+
+	return cells
+}
+
+// distribute links the blocks of a floorplan with the cells of a grid whose
+// thermal nodes start from a particular offset. The power of a block is spread
+// over the cells that the block overlaps in proportion to the area of the
+// overlap. The temperature of a block is either the average temperature of the
+// same cells weighted in the same way or the temperature of the cell
+// containing the center of the block (see Mapping in Specs).
+func distribute(blocks, cells []Block, offset uint, specs *Specs) ([][]Link, [][]Link) {
+	nr, nc := specs.Rows, specs.Columns
+	left, bottom := cells[0].Left, cells[0].Bottom
+	w, h := cells[0].Width, cells[0].Height
+
+	inputs := make([][]Link, len(blocks))
+	outputs := make([][]Link, len(blocks))
+	for i := range blocks {
+		block := &blocks[i]
+
+		links, total := []Link{}, 0.0
+		for j := range cells {
+			area := overlap(block, &cells[j])
+			if area <= tolerance*tolerance {
+				continue
+			}
+			links = append(links, Link{Node: offset + uint(j), Weight: area})
+			total += area
+		}
+		for j := range links {
+			links[j].Weight /= total
+		}
+		inputs[i] = links
+
+		switch specs.Mapping {
+		case "avg":
+			outputs[i] = links
+		default:
+			x := block.Left + block.Width/2
+			y := block.Bottom + block.Height/2
+			j := clamp(math.Floor((x-left)/w), nc)
+			k := clamp(math.Floor((y-bottom)/h), nr)
+			outputs[i] = []Link{Link{Node: offset + k*nc + j, Weight: 1}}
+		}
+	}
+
+	return inputs, outputs
+}
+
+func clamp(x float64, n uint) uint {
+	if x < 0 {
+		return 0
+	}
+	if x > float64(n-1) {
+		return n - 1
+	}
+	return uint(x)
+}
+
+func overlap(one, other *Block) float64 {
+	w := math.Min(one.Left+one.Width, other.Left+other.Width) - math.Max(one.Left, other.Left)
+	h := math.Min(one.Bottom+one.Height, other.Bottom+other.Height) -
+		math.Max(one.Bottom, other.Bottom)
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
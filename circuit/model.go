@@ -0,0 +1,663 @@
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Model is a thermal RC circuit.
+type Model struct {
+	// The blocks of the floorplan.
+	Blocks []Block
+
+	// The thermal nodes of the circuit.
+	Nodes []Node
+
+	// The capacitance of the thermal nodes.
+	C []float64 // in J/K
+
+	// The conductance matrix, which is stored in column-major order and
+	// includes the conductance to the ambience on the diagonal.
+	G []float64 // in W/K
+
+	// The thermal nodes dissipating the power of each block along with the
+	// shares of the power.
+	Inputs [][]Link
+
+	// The thermal nodes determining the temperature of each block along with
+	// the weights of their temperatures.
+	Outputs [][]Link
+
+	// The source of the temperature of the thermal nodes at the beginning of a
+	// simulation (see Initial).
+	initialTemperature float64
+	initialFile        string
+}
+
+// Link is a weighted reference to a thermal node.
+type Link struct {
+	Node   uint
+	Weight float64
+}
+
+const (
+	// The fitting factor of the lumped capacitance of HotSpot.
+	capacitanceFactor = 0.333
+
+	// The tolerance for comparing the coordinates of blocks.
+	tolerance = 1e-6
+)
+
+// The peripheral nodes in the order used by HotSpot.
+const (
+	spreaderWest = iota
+	spreaderEast
+	spreaderNorth
+	spreaderSouth
+	sinkInnerWest
+	sinkInnerEast
+	sinkInnerNorth
+	sinkInnerSouth
+	sinkOuterWest
+	sinkOuterEast
+	sinkOuterNorth
+	sinkOuterSouth
+)
+
+// The peripheral nodes of the secondary heat path.
+const (
+	substrateWest = iota
+	substrateEast
+	substrateNorth
+	substrateSouth
+	solderWest
+	solderEast
+	solderNorth
+	solderSouth
+	boardInnerWest
+	boardInnerEast
+	boardInnerNorth
+	boardInnerSouth
+	boardOuterWest
+	boardOuterEast
+	boardOuterNorth
+	boardOuterSouth
+)
+
+// The thermal properties of the materials of the secondary heat path, in
+// W/(m K) and J/(m^3 K).
+const (
+	metalConductivity     = 12.0
+	metalHeat             = 3.55e6
+	bumpConductivity      = 50.0
+	bumpHeat              = 1.67e6
+	underfillConductivity = 0.8
+	underfillHeat         = 2.1e6
+	substrateConductivity = 1.6
+	substrateHeat         = 1.6e6
+	solderConductivity    = 15.0
+	solderHeat            = 2.1e6
+	boardConductivity     = 20.0
+	boardHeat             = 1.32e6
+)
+
+// Build constructs the thermal RC circuit of a floorplan according to the block
+// model of HotSpot, which is applied either to the blocks of the floorplan or
+// to the cells of a grid dividing the die (see Rows and Columns in Specs).
+// Each block or cell corresponds to one thermal node per layer.
+func Build(blocks []Block, specs *Specs) (*Model, error) {
+	layers := []Layer{
+		Layer{
+			Blocks:       blocks,
+			Thickness:    specs.ChipThickness,
+			Conductivity: specs.ChipConductivity,
+			Heat:         specs.ChipHeat,
+			Lateral:      !specs.OmitLateral,
+			Power:        true,
+		},
+		Layer{
+			Blocks:       blocks,
+			Thickness:    specs.InterfaceThickness,
+			Conductivity: specs.InterfaceConductivity,
+			Heat:         specs.InterfaceHeat,
+			Lateral:      true,
+		},
+	}
+
+	model, cells, err := construct(layers, specs)
+	if err != nil {
+		return nil, err
+	}
+
+	model.Nodes = Nodes(cells)
+	if specs.Secondary {
+		model.Nodes = append(model.Nodes, secondaryNodes(cells)...)
+	}
+
+	return model, nil
+}
+
+// BuildStack constructs the thermal RC circuit of a stacked die (see Layer)
+// placed on top of the heat spreader and heat sink in the same way as Build
+// does for a planar die, which corresponds to a stack of the die and the
+// thermal interface material. The blocks of the model are the blocks of the
+// layers dissipating power listed in the order of the layers. In the block
+// model, all the layers should have the same floorplan; with a grid (see Rows
+// and Columns in Specs), the floorplans can differ.
+func BuildStack(layers []Layer, specs *Specs) (*Model, error) {
+	model, cells, err := construct(layers, specs)
+	if err != nil {
+		return nil, err
+	}
+
+	model.Nodes = stackNodes(cells, uint(len(layers)))
+	if specs.Secondary {
+		model.Nodes = append(model.Nodes, secondaryNodes(cells)...)
+	}
+
+	return model, nil
+}
+
+// construct computes everything but the thermal nodes of the circuit of a
+// stacked die and returns the cells composing each layer of the die.
+func construct(layers []Layer, specs *Specs) (*Model, []Block, error) {
+	if len(layers) == 0 {
+		return nil, nil, errors.New("the die should contain at least one layer")
+	}
+	if err := specs.check(); err != nil {
+		return nil, nil, err
+	}
+
+	left, bottom := math.Inf(1), math.Inf(1)
+	right, top := math.Inf(-1), math.Inf(-1)
+	for _, layer := range layers {
+		if len(layer.Blocks) == 0 {
+			return nil, nil, errors.New("the floorplan should contain at least one block")
+		}
+		values := []float64{layer.Thickness, layer.Conductivity, layer.Heat}
+		for _, value := range values {
+			if math.IsNaN(value) || math.IsInf(value, 0) || value <= 0 {
+				return nil, nil, errors.New("the parameters of the layers should be positive")
+			}
+		}
+		for _, block := range layer.Blocks {
+			if !(block.Width > 0) || !(block.Height > 0) {
+				return nil, nil, errors.New("the width and height of the blocks should be positive")
+			}
+			left = math.Min(left, block.Left)
+			bottom = math.Min(bottom, block.Bottom)
+			right = math.Max(right, block.Left+block.Width)
+			top = math.Max(top, block.Bottom+block.Height)
+		}
+	}
+	if math.IsInf(right-left, 0) || math.IsInf(top-bottom, 0) {
+		return nil, nil, errors.New("the coordinates of the blocks should be finite")
+	}
+
+	if right-left > specs.SpreaderSide || top-bottom > specs.SpreaderSide {
+		return nil, nil, errors.New("the floorplan should fit within the heat spreader")
+	}
+	if specs.Secondary {
+		side := math.Min(specs.SubstrateSide, specs.SolderSide)
+		if right-left > side || top-bottom > side {
+			return nil, nil, errors.New("the floorplan should fit within the package substrate and solder balls")
+		}
+	}
+
+	model := &Model{
+		initialTemperature: specs.InitialTemperature,
+		initialFile:        specs.InitialFile,
+	}
+
+	seen := make(map[string]bool)
+	for _, layer := range layers {
+		if !layer.Power {
+			continue
+		}
+		for _, block := range layer.Blocks {
+			if seen[block.Name] {
+				return nil, nil, fmt.Errorf("the block %q is defined more than once", block.Name)
+			}
+			seen[block.Name] = true
+		}
+		model.Blocks = append(model.Blocks, layer.Blocks...)
+	}
+	if len(model.Blocks) == 0 {
+		return nil, nil, errors.New("at least one layer should dissipate power")
+	}
+
+	cells := layers[0].Blocks
+	if specs.grid() {
+		cells = divide(left, bottom, right, top, specs.Rows, specs.Columns)
+	} else {
+		for _, layer := range layers[1:] {
+			if !coincide(layer.Blocks, cells) {
+				return nil, nil, errors.New("the layers should have the same floorplan in the block model")
+			}
+		}
+	}
+
+	nc := uint(len(cells))
+	for k, layer := range layers {
+		if !layer.Power {
+			continue
+		}
+		var inputs, outputs [][]Link
+		if specs.grid() {
+			inputs, outputs = distribute(layer.Blocks, cells, uint(k)*nc, specs)
+		} else {
+			inputs = make([][]Link, nc)
+			for i := range inputs {
+				inputs[i] = []Link{Link{Node: uint(k)*nc + uint(i), Weight: 1}}
+			}
+			outputs = inputs
+		}
+		model.Inputs = append(model.Inputs, inputs...)
+		model.Outputs = append(model.Outputs, outputs...)
+	}
+
+	model.C, model.G = assemble(cells, layers, left, bottom, right, top, specs)
+
+	return model, cells, nil
+}
+
+// assemble computes the capacitance and conductance of a circuit whose die is
+// composed of particular layers, each of which is divided into particular
+// cells, which are either the blocks of a floorplan or the cells of a grid.
+func assemble(cells []Block, stack []Layer, left, bottom, right, top float64,
+	specs *Specs) ([]float64, []float64) {
+
+	w, h := right-left, top-bottom
+	s1, s2 := specs.SpreaderSide, specs.SinkSide
+
+	// The layers of the die are followed by the heat spreader and heat sink,
+	// which constitute the primary heat path.
+	nd := uint(len(stack))
+	np := nd + 2
+
+	nb := uint(len(cells))
+	nn := np*nb + peripheral
+	if specs.Secondary {
+		nn += 5*nb + secondaryPeripheral
+	}
+
+	C := make([]float64, nn)
+	G := make([]float64, nn*nn)
+	A := make([]float64, nn)
+
+	// The conductance between two nodes is composed of two halves, one on the
+	// side of each node, which are connected in series. The arithmetic follows
+	// HotSpot closely so that the results coincide.
+	connect := func(i, j uint, gi, gj float64) {
+		G[j*nn+i] = -1 / (1/gi + 1/gj)
+		G[i*nn+j] = G[j*nn+i]
+	}
+	ground := func(i uint, g float64) {
+		A[i] = g
+	}
+
+	type layer struct {
+		thickness    float64
+		conductivity float64
+		heat         float64
+	}
+
+	layers := make([]layer, 0, np)
+	for i := range stack {
+		layers = append(layers, layer{stack[i].Thickness, stack[i].Conductivity, stack[i].Heat})
+	}
+	layers = append(layers, []layer{
+		{specs.SpreaderThickness, specs.SpreaderConductivity, specs.SpreaderHeat},
+		{specs.SinkThickness, specs.SinkConductivity, specs.SinkHeat},
+	}...)
+	if specs.Secondary {
+		// The C4 pads and the underfill between them are blended into one
+		// layer according to the share of the area occupied by the pads.
+		share := math.Min(1, float64(specs.BumpCount)*specs.BumpSide*specs.BumpSide/(w*h))
+		layers = append(layers, []layer{
+			{float64(specs.MetalLayers) * specs.MetalThickness, metalConductivity, metalHeat},
+			{specs.BumpThickness, share*bumpConductivity + (1-share)*underfillConductivity,
+				share*bumpHeat + (1-share)*underfillHeat},
+			{specs.SubstrateThickness, substrateConductivity, substrateHeat},
+			{specs.SolderThickness, solderConductivity, solderHeat},
+			{specs.BoardThickness, boardConductivity, boardHeat},
+		}...)
+	}
+	nl := uint(len(layers))
+
+	// The layers of the primary heat path are followed by its peripheral
+	// nodes, which are followed by the layers of the secondary heat path.
+	node := func(k, i uint) uint {
+		if k < np {
+			return k*nb + i
+		}
+		return (k-np)*nb + np*nb + peripheral + i
+	}
+
+	// The conductance from the center of a cell to its edges in each layer.
+	gx := make([]float64, nl*nb)
+	gy := make([]float64, nl*nb)
+	for k, layer := range layers {
+		for i, cell := range cells {
+			gx[uint(k)*nb+uint(i)] = 1 / resistance(layer.conductivity, cell.Width/2,
+				cell.Height*layer.thickness)
+			gy[uint(k)*nb+uint(i)] = 1 / resistance(layer.conductivity, cell.Height/2,
+				cell.Width*layer.thickness)
+		}
+	}
+
+	// The cells lying on the edges of the die.
+	border := make([][4]bool, nb)
+	gw, ge := make([]float64, nl), make([]float64, nl)
+	gn, gs := make([]float64, nl), make([]float64, nl)
+	for i, cell := range cells {
+		if equal(cell.Left, left) {
+			border[i][0] = true
+			for k := uint(0); k < nl; k++ {
+				gw[k] += gx[k*nb+uint(i)]
+			}
+		}
+		if equal(cell.Left+cell.Width, right) {
+			border[i][1] = true
+			for k := uint(0); k < nl; k++ {
+				ge[k] += gx[k*nb+uint(i)]
+			}
+		}
+		if equal(cell.Bottom+cell.Height, top) {
+			border[i][2] = true
+			for k := uint(0); k < nl; k++ {
+				gn[k] += gy[k*nb+uint(i)]
+			}
+		}
+		if equal(cell.Bottom, bottom) {
+			border[i][3] = true
+			for k := uint(0); k < nl; k++ {
+				gs[k] += gy[k*nb+uint(i)]
+			}
+		}
+	}
+
+	// The lateral conductance between adjacent cells.
+	for i := uint(0); i < nb; i++ {
+		for j := i + 1; j < nb; j++ {
+			length, horizontal := shared(&cells[i], &cells[j])
+			if length < tolerance {
+				continue
+			}
+			for k := uint(0); k < nl; k++ {
+				if k < nd && !stack[k].Lateral {
+					continue
+				}
+				var gi, gj float64
+				if horizontal {
+					gi = gx[k*nb+i] / cells[i].Height * length
+					gj = gx[k*nb+j] / cells[j].Height * length
+				} else {
+					gi = gy[k*nb+i] / cells[i].Width * length
+					gj = gy[k*nb+j] / cells[j].Width * length
+				}
+				connect(node(k, i), node(k, j), gi, gj)
+			}
+		}
+	}
+
+	// The package outside the die.
+	ax := (s1 + h) * (s1 - w) / 4
+	ay := (s1 + w) * (s1 - h) / 4
+	ap := (s2*s2 - s1*s1) / 4
+
+	ts, ks := specs.SpreaderThickness, specs.SpreaderConductivity
+	tk, kk := specs.SinkThickness, specs.SinkConductivity
+	rc, cc := specs.ConvectionResistance, specs.ConvectionCapacitance
+
+	rsx := resistance(ks, (s1-w)/4, (s1+3*h)/4*ts)
+	rsy := resistance(ks, (s1-h)/4, (s1+3*w)/4*ts)
+	rkx1 := resistance(kk, (s1-w)/4, (s1+3*h)/4*tk)
+	rky1 := resistance(kk, (s1-h)/4, (s1+3*w)/4*tk)
+	rkx2 := resistance(kk, (s1-w)/4, (3*s1+h)/4*tk)
+	rky2 := resistance(kk, (s1-h)/4, (3*s1+w)/4*tk)
+	rk := resistance(kk, (s2-s1)/4, (s2+3*s1)/4*tk)
+
+	// The layers extending beyond the die along with their peripheral nodes
+	// and the lateral resistance from the edges of the die to the nodes.
+	type ring struct {
+		layer, node uint
+		rx, ry      float64
+	}
+
+	rings := []ring{
+		{nd, np*nb + spreaderWest, rsx, rsy},
+		{nd + 1, np*nb + sinkInnerWest, rkx1, rky1},
+	}
+
+	// The secondary heat path mirrors the primary one: the package substrate
+	// and the solder balls play the role of the heat spreader, and the
+	// printed circuit board plays the role of the heat sink. As the inner ring
+	// of the heat sink lies under the heat spreader, the inner ring of the
+	// board lies under the solder balls, so its lateral resistance and area
+	// are given by the side of the solder balls, and only the outer ring
+	// extends to the side of the board, which follows populate_package_R and
+	// populate_package_C of HotSpot (r_pcb1_x, r_pcb1_y, and r_pcb).
+	var ss, ts1, ks1, so, ts2, ks2, sp, tp, kp, rcs, ccs float64
+	if specs.Secondary {
+		ss, ts1, ks1 = specs.SubstrateSide, specs.SubstrateThickness, substrateConductivity
+		so, ts2, ks2 = specs.SolderSide, specs.SolderThickness, solderConductivity
+		sp, tp, kp = specs.BoardSide, specs.BoardThickness, boardConductivity
+		rcs, ccs = specs.SecondaryConvectionResistance, specs.SecondaryConvectionCapacitance
+
+		q := (np+5)*nb + peripheral
+		rings = append(rings, []ring{
+			{np + 2, q + substrateWest,
+				resistance(ks1, (ss-w)/4, (ss+3*h)/4*ts1),
+				resistance(ks1, (ss-h)/4, (ss+3*w)/4*ts1)},
+			{np + 3, q + solderWest,
+				resistance(ks2, (so-w)/4, (so+3*h)/4*ts2),
+				resistance(ks2, (so-h)/4, (so+3*w)/4*ts2)},
+			{np + 4, q + boardInnerWest,
+				resistance(kp, (so-w)/4, (so+3*h)/4*tp),
+				resistance(kp, (so-h)/4, (so+3*w)/4*tp)},
+		}...)
+	}
+
+	for i, cell := range cells {
+		i := uint(i)
+		area := cell.Width * cell.Height
+
+		// The vertical conductance between the layers and to the ambience.
+		for k := uint(0); k < np-1; k++ {
+			g := 2 / resistance(layers[k].conductivity, layers[k].thickness, area)
+			connect(k*nb+i, (k+1)*nb+i, g, g)
+		}
+		ground((np-1)*nb+i, 1/(resistance(kk, tk, area)+rc*(s2*s2)/area))
+
+		// The lateral conductance from the edges of the die to the periphery.
+		edge := func(k, j uint, g, r, sum float64) {
+			g = 2 / (1/g + r*sum/g)
+			connect(node(k, i), j, g, g)
+		}
+		for _, ring := range rings {
+			k, p := ring.layer, ring.node
+			if border[i][0] {
+				edge(k, p+spreaderWest, gx[k*nb+i], ring.rx, gw[k])
+			}
+			if border[i][1] {
+				edge(k, p+spreaderEast, gx[k*nb+i], ring.rx, ge[k])
+			}
+			if border[i][2] {
+				edge(k, p+spreaderNorth, gy[k*nb+i], ring.ry, gn[k])
+			}
+			if border[i][3] {
+				edge(k, p+spreaderSouth, gy[k*nb+i], ring.ry, gs[k])
+			}
+		}
+
+		for k := uint(0); k < np-1; k++ {
+			C[k*nb+i] = capacitanceFactor * layers[k].heat * layers[k].thickness * area
+		}
+		C[(np-1)*nb+i] = capacitanceFactor*(specs.SinkHeat*tk*area) + capacitanceFactor*cc/(s2*s2)*area
+
+		if !specs.Secondary {
+			continue
+		}
+
+		// The secondary heat path starts at the bottom of the die, which is the
+		// side of the first layer.
+		for k := np; k < nl; k++ {
+			j := uint(0)
+			if k > np {
+				j = k - 1
+			}
+			gi := 2 / resistance(layers[j].conductivity, layers[j].thickness, area)
+			gj := 2 / resistance(layers[k].conductivity, layers[k].thickness, area)
+			connect(node(j, i), node(k, i), gi, gj)
+		}
+		ground(node(nl-1, i), 1/(resistance(kp, tp, area)+rcs*(sp*sp)/area))
+
+		for k := np; k < nl-1; k++ {
+			C[node(k, i)] = capacitanceFactor * layers[k].heat * layers[k].thickness * area
+		}
+		C[node(nl-1, i)] = capacitanceFactor*(boardHeat*tp*area) + capacitanceFactor*ccs/(sp*sp)*area
+	}
+
+	// The peripheral nodes.
+	p := np * nb
+	for _, direction := range []struct {
+		area, rs, rk, rkc float64
+		spreader, inner   uint
+	}{
+		{ax, resistance(ks, ts, ax), resistance(kk, tk, ax), rkx2, spreaderWest, sinkInnerWest},
+		{ax, resistance(ks, ts, ax), resistance(kk, tk, ax), rkx2, spreaderEast, sinkInnerEast},
+		{ay, resistance(ks, ts, ay), resistance(kk, tk, ay), rky2, spreaderNorth, sinkInnerNorth},
+		{ay, resistance(ks, ts, ay), resistance(kk, tk, ay), rky2, spreaderSouth, sinkInnerSouth},
+	} {
+		outer := direction.inner + 4
+
+		g := 2 / direction.rs
+		connect(p+direction.spreader, p+direction.inner, g, g)
+		g = 2 / (rk + direction.rkc)
+		connect(p+direction.inner, p+outer, g, g)
+
+		ground(p+direction.inner, 1/(direction.rk+rc*(s2*s2)/direction.area))
+		ground(p+outer, 1/(resistance(kk, tk, ap)+rc*(s2*s2)/ap))
+
+		C[p+direction.spreader] = capacitanceFactor * specs.SpreaderHeat * ts * direction.area
+		C[p+direction.inner] = capacitanceFactor * (specs.SinkHeat*tk + cc/(s2*s2)) * direction.area
+		C[p+outer] = capacitanceFactor * (specs.SinkHeat*tk + cc/(s2*s2)) * ap
+	}
+
+	// The peripheral nodes of the secondary heat path.
+	if specs.Secondary {
+		p := (np+5)*nb + peripheral
+		rb := resistance(kp, (sp-so)/4, (sp+3*so)/4*tp)
+		ab := (sp*sp - so*so) / 4
+		for _, direction := range []struct {
+			substrate, solder, rbc float64
+			index                  uint
+		}{
+			{(ss + h) * (ss - w) / 4, (so + h) * (so - w) / 4,
+				resistance(kp, (so-w)/4, (3*so+h)/4*tp), substrateWest},
+			{(ss + h) * (ss - w) / 4, (so + h) * (so - w) / 4,
+				resistance(kp, (so-w)/4, (3*so+h)/4*tp), substrateEast},
+			{(ss + w) * (ss - h) / 4, (so + w) * (so - h) / 4,
+				resistance(kp, (so-h)/4, (3*so+w)/4*tp), substrateNorth},
+			{(ss + w) * (ss - h) / 4, (so + w) * (so - h) / 4,
+				resistance(kp, (so-h)/4, (3*so+w)/4*tp), substrateSouth},
+		} {
+			substrate := p + direction.index
+			solder := substrate + solderWest
+			inner := substrate + boardInnerWest
+			outer := substrate + boardOuterWest
+
+			connect(substrate, solder, 2/resistance(ks1, ts1, direction.substrate),
+				2/resistance(ks2, ts2, direction.solder))
+			connect(solder, inner, 2/resistance(ks2, ts2, direction.solder),
+				2/resistance(kp, tp, direction.solder))
+			g := 2 / (rb + direction.rbc)
+			connect(inner, outer, g, g)
+
+			ground(inner, 1/(resistance(kp, tp, direction.solder)+rcs*(sp*sp)/direction.solder))
+			ground(outer, 1/(resistance(kp, tp, ab)+rcs*(sp*sp)/ab))
+
+			C[substrate] = capacitanceFactor * substrateHeat * ts1 * direction.substrate
+			C[solder] = capacitanceFactor * solderHeat * ts2 * direction.solder
+			C[inner] = capacitanceFactor * (boardHeat*tp + ccs/(sp*sp)) * direction.solder
+			C[outer] = capacitanceFactor * (boardHeat*tp + ccs/(sp*sp)) * ab
+		}
+	}
+
+	// The diagonal accumulates the conductance to the ambience and to the
+	// other nodes.
+	for i := uint(0); i < nn; i++ {
+		G[i*nn+i] = A[i]
+		for j := uint(0); j < nn; j++ {
+			if i != j {
+				G[i*nn+i] -= G[j*nn+i]
+			}
+		}
+	}
+
+	return C, G
+}
+
+// Links returns the links of the blocks with particular indices in the
+// floorplan (see Select).
+func Links(links [][]Link, indices []uint) [][]Link {
+	selection := make([][]Link, len(indices))
+	for i, j := range indices {
+		selection[i] = links[j]
+	}
+	return selection
+}
+
+// Measure returns the sum of the elements of a vector X referenced by links
+// weighted by the weights of the links. When the links are the outputs of a
+// block (see Model), and X is the temperature of the thermal nodes, the result
+// is the temperature of the block.
+func Measure(links []Link, X []float64) float64 {
+	sum := 0.0
+	for _, link := range links {
+		sum += link.Weight * X[link.Node]
+	}
+	return sum
+}
+
+func equal(x, y float64) bool {
+	return math.Abs(x-y) < tolerance
+}
+
+// coincide checks if two floorplans consist of the same blocks geometrically.
+func coincide(one, other []Block) bool {
+	if len(one) != len(other) {
+		return false
+	}
+	for i := range one {
+		if !equal(one[i].Left, other[i].Left) || !equal(one[i].Bottom, other[i].Bottom) ||
+			!equal(one[i].Width, other[i].Width) || !equal(one[i].Height, other[i].Height) {
+			return false
+		}
+	}
+	return true
+}
+
+func resistance(conductivity, thickness, area float64) float64 {
+	return thickness / (conductivity * area)
+}
+
+// shared returns the length of the common edge of two blocks and whether the
+// blocks are adjacent horizontally; blocks touching only at a corner share
+// nothing.
+func shared(one, other *Block) (float64, bool) {
+	x1, x2 := one.Left, one.Left+one.Width
+	x3, x4 := other.Left, other.Left+other.Width
+	y1, y2 := one.Bottom, one.Bottom+one.Height
+	y3, y4 := other.Bottom, other.Bottom+other.Height
+
+	if equal(x2, x3) || equal(x1, x4) {
+		return math.Min(y2, y4) - math.Max(y1, y3), true
+	}
+	if equal(y2, y3) || equal(y1, y4) {
+		return math.Min(x2, x4) - math.Max(x1, x3), false
+	}
+	return 0, false
+}
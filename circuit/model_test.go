@@ -0,0 +1,321 @@
+package circuit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestBuild(t *testing.T) {
+	blocks, _ := LoadFloorplan(findFixture("002.flp"))
+
+	model, err := Build(blocks, DefaultSpecs())
+	assert.Equal(err, nil, t)
+
+	nn := uint(len(model.Nodes))
+	assert.Equal(nn, uint(4*2+12), t)
+
+	assert.Close(model.C, []float64{
+		3.4964999999999996e-04, 3.4964999999999996e-04,
+		1.0656000000000000e-04, 1.0656000000000000e-04,
+		4.7286000000000003e-03, 4.7286000000000003e-03,
+		8.4575339999999999e-02, 8.4575339999999999e-02,
+		2.4588720000000000e-01, 2.4588720000000000e-01,
+		2.8135169999999998e-01, 2.8135169999999998e-01,
+		4.3979176799999999e+00, 4.3979176799999999e+00,
+		5.0322327300000005e+00, 5.0322327300000005e+00,
+		1.4272088625000002e+01, 1.4272088625000002e+01,
+		1.4272088625000002e+01, 1.4272088625000002e+01,
+	}, 1e-14, t)
+
+	assert.Close(model.G[0], 2.6816666666666666, 1e-14, t)
+	assert.Close(model.G[1], -1.5e-02, 1e-14, t)
+}
+
+func TestBuildInitial(t *testing.T) {
+	blocks, _ := LoadFloorplan(findFixture("002.flp"))
+
+	specs := DefaultSpecs()
+	specs.InitialTemperature = 300
+	model, err := Build(blocks, specs)
+	assert.Equal(err, nil, t)
+	Q, err := model.Initial()
+	assert.Equal(err, nil, t)
+	for _, q := range Q {
+		assert.Equal(q, 300.0, t)
+	}
+
+	specs.InitialFile = findFixture("002.init")
+	model, err = Build(blocks, specs)
+	assert.Equal(err, nil, t)
+	Q, err = model.Initial()
+	assert.Equal(err, nil, t)
+	assert.Equal(Q[0], 340.25, t)
+
+	specs.Secondary = true
+	model, err = Build(blocks, specs)
+	assert.Equal(err, nil, t)
+	_, err = model.Initial()
+	assert.Equal(err != nil, true, t)
+
+	specs.InitialFile = findFixture("missing.init")
+	model, err = Build(blocks, specs)
+	assert.Equal(err, nil, t)
+	_, err = model.Initial()
+	assert.Equal(err != nil, true, t)
+
+	specs = DefaultSpecs()
+	specs.InitialTemperature = 0
+	_, err = Build(blocks, specs)
+	assert.Equal(err != nil, true, t)
+}
+
+func TestBuildConservation(t *testing.T) {
+	blocks := []Block{
+		Block{Name: "core0", Width: 0.002, Height: 0.001, Left: 0.000, Bottom: 0.000},
+		Block{Name: "core1", Width: 0.002, Height: 0.001, Left: 0.002, Bottom: 0.000},
+		Block{Name: "cache", Width: 0.004, Height: 0.002, Left: 0.000, Bottom: 0.001},
+	}
+
+	model, err := Build(blocks, DefaultSpecs())
+	assert.Equal(err, nil, t)
+
+	nb, nn := uint(len(blocks)), uint(len(model.Nodes))
+	for i := uint(0); i < nn; i++ {
+		sum := 0.0
+		for j := uint(0); j < nn; j++ {
+			assert.Equal(model.G[j*nn+i], model.G[i*nn+j], t)
+			sum += model.G[j*nn+i]
+		}
+		grounded := i >= 3*nb && i < 4*nb || i >= 4*nb+sinkInnerWest
+		assert.Equal(sum > 1e-12, grounded, t)
+		if !grounded {
+			assert.Equal(math.Abs(sum) < 1e-9*model.G[i*nn+i], true, t)
+		}
+	}
+
+	assert.Equal(model.G[2*nn+0] < 0, true, t)
+	assert.Equal(model.G[2*nn+1] < 0, true, t)
+	assert.Equal(model.G[1*nn+0] < 0, true, t)
+
+	specs := DefaultSpecs()
+	specs.OmitLateral = true
+	model, _ = Build(blocks, specs)
+	assert.Equal(model.G[2*nn+0], 0.0, t)
+	assert.Equal(model.G[1*nn+0], 0.0, t)
+	assert.Equal(model.G[(nb+2)*nn+nb] < 0, true, t)
+}
+
+func TestBuildSecondary(t *testing.T) {
+	blocks, _ := LoadFloorplan(findFixture("002.flp"))
+
+	primary, _ := Build(blocks, DefaultSpecs())
+
+	specs := DefaultSpecs()
+	specs.Secondary = true
+
+	model, err := Build(blocks, specs)
+	assert.Equal(err, nil, t)
+
+	nb, nn := uint(len(blocks)), uint(len(model.Nodes))
+	assert.Equal(nn, uint(9*2+12+16), t)
+	assert.Equal(model.Nodes[:4*nb+12], primary.Nodes, t)
+	assert.Equal(model.Nodes[4*nb+12], Node{Name: "metal_core0", Kind: Metal}, t)
+
+	p, q := 4*nb+peripheral, 9*nb+peripheral
+	for i := uint(0); i < nn; i++ {
+		assert.Equal(model.C[i] > 0, true, t)
+		sum := 0.0
+		for j := uint(0); j < nn; j++ {
+			assert.Equal(model.G[j*nn+i], model.G[i*nn+j], t)
+			sum += model.G[j*nn+i]
+		}
+		grounded := i >= 3*nb && i < 4*nb || i >= 4*nb+sinkInnerWest && i < p ||
+			i >= p+4*nb && i < q || i >= q+boardInnerWest
+		assert.Equal(sum > 1e-12, grounded, t)
+	}
+
+	assert.Equal(model.G[p*nn+0] < 0, true, t)
+	assert.Equal(model.G[(p+2*nb)*nn+q+substrateWest] < 0, true, t)
+	assert.Equal(model.G[(p+2*nb)*nn+q+substrateNorth] < 0, true, t)
+
+	specs.SolderSide = 2 * specs.BoardSide
+	_, err = Build(blocks, specs)
+	assert.Equal(err != nil, true, t)
+
+	specs = DefaultSpecs()
+	specs.Secondary, specs.SubstrateSide = true, 0.003
+	_, err = Build(blocks, specs)
+	assert.Equal(err != nil, true, t)
+}
+
+func TestBuildGrid(t *testing.T) {
+	blocks, _ := LoadFloorplan(findFixture("002.flp"))
+
+	block, _ := Build(blocks, DefaultSpecs())
+
+	specs := DefaultSpecs()
+	specs.Rows, specs.Columns = 1, 2
+
+	grid, err := Build(blocks, specs)
+	assert.Equal(err, nil, t)
+	assert.Equal(len(grid.Nodes), len(block.Nodes), t)
+	assert.Close(grid.C, block.C, 1e-14, t)
+	assert.Close(grid.G, block.G, 1e-10, t)
+	assert.Equal(grid.Inputs, block.Inputs, t)
+	assert.Equal(grid.Outputs, block.Outputs, t)
+
+	specs.Rows, specs.Columns = 2, 4
+
+	grid, err = Build(blocks, specs)
+	assert.Equal(err, nil, t)
+	assert.Equal(len(grid.Nodes), 4*8+12, t)
+	assert.Equal(grid.Inputs[1], []Link{
+		Link{Node: 2, Weight: 0.25}, Link{Node: 3, Weight: 0.25},
+		Link{Node: 6, Weight: 0.25}, Link{Node: 7, Weight: 0.25},
+	}, t)
+	assert.Equal(grid.Outputs[1], []Link{Link{Node: 7, Weight: 1}}, t)
+
+	specs.Mapping = "avg"
+
+	grid, _ = Build(blocks, specs)
+	assert.Equal(grid.Outputs[1], grid.Inputs[1], t)
+}
+
+func TestBuildStack(t *testing.T) {
+	blocks, _ := LoadFloorplan(findFixture("002.flp"))
+	layers, _ := LoadLayers(findFixture("002.lcf"))
+
+	planar, _ := Build(blocks, DefaultSpecs())
+
+	model, err := BuildStack(layers, DefaultSpecs())
+	assert.Equal(err, nil, t)
+	assert.Equal(model.Blocks, planar.Blocks, t)
+	assert.Equal(model.Inputs, planar.Inputs, t)
+	assert.Close(model.C, planar.C, 1e-15, t)
+	assert.Close(model.G, planar.G, 1e-12, t)
+	assert.Equal(model.Nodes[2], Node{Name: "layer1_core0", Kind: Die}, t)
+
+	layers, _ = LoadLayers(findFixture("003.lcf"))
+
+	model, err = BuildStack(layers, DefaultSpecs())
+	assert.Equal(err, nil, t)
+
+	nn := uint(len(model.Nodes))
+	assert.Equal(nn, uint(6*2+12), t)
+	assert.Equal(len(model.Blocks), 4, t)
+	assert.Equal(model.Blocks[2].Name, "core0", t)
+	assert.Equal(model.Inputs[1], []Link{Link{Node: 1, Weight: 1}}, t)
+	assert.Equal(model.Inputs[2], []Link{Link{Node: 4, Weight: 1}}, t)
+	assert.Equal(model.G[1*nn+0] < 0, true, t)
+	assert.Equal(model.G[3*nn+2], 0.0, t)
+	assert.Equal(model.G[2*nn+0] < 0, true, t)
+
+	layers[2].Blocks, _ = LoadFloorplan(findFixture("003.flp"))
+	_, err = BuildStack(layers, DefaultSpecs())
+	assert.Equal(err != nil, true, t)
+
+	specs := DefaultSpecs()
+	specs.Rows, specs.Columns = 3, 4
+
+	model, err = BuildStack(layers, specs)
+	assert.Equal(err, nil, t)
+	assert.Equal(len(model.Nodes), 6*12+12, t)
+	assert.Equal(model.Inputs[0], []Link{
+		Link{Node: 0, Weight: 0.25}, Link{Node: 1, Weight: 0.25},
+		Link{Node: 4, Weight: 0.25}, Link{Node: 5, Weight: 0.25},
+	}, t)
+	assert.Equal(model.Inputs[2], []Link{
+		Link{Node: 2*12 + 0, Weight: 0.5}, Link{Node: 2*12 + 1, Weight: 0.5},
+	}, t)
+
+	layers[0].Power, layers[2].Power = false, false
+	_, err = BuildStack(layers, specs)
+	assert.Equal(err != nil, true, t)
+
+	layers[0].Power, layers[2].Power = true, true
+	layers[2].Blocks = layers[0].Blocks
+	_, err = BuildStack(layers, specs)
+	assert.Equal(err != nil, true, t)
+}
+
+func TestBuildInvalid(t *testing.T) {
+	blocks := []Block{Block{Name: "core0", Width: 0.002, Height: 0.002}}
+
+	_, err := Build(nil, DefaultSpecs())
+	assert.Equal(err != nil, true, t)
+
+	_, err = Build([]Block{Block{Name: "core0", Width: 0.1, Height: 0.002}}, DefaultSpecs())
+	assert.Equal(err != nil, true, t)
+
+	specs := DefaultSpecs()
+	specs.ChipThickness = 0
+	_, err = Build(blocks, specs)
+	assert.Equal(err != nil, true, t)
+
+	specs = DefaultSpecs()
+	specs.SpreaderSide = 2 * specs.SinkSide
+	_, err = Build(blocks, specs)
+	assert.Equal(err != nil, true, t)
+
+	specs = DefaultSpecs()
+	specs.Model = "mesh"
+	_, err = Build(blocks, specs)
+	assert.Equal(err != nil, true, t)
+
+	specs = DefaultSpecs()
+	specs.Model = "grid"
+	_, err = Build(blocks, specs)
+	assert.Equal(err != nil, true, t)
+
+	specs = DefaultSpecs()
+	specs.Columns = 4
+	_, err = Build(blocks, specs)
+	assert.Equal(err != nil, true, t)
+}
+
+func TestMeasure(t *testing.T) {
+	links := []Link{Link{Node: 1, Weight: 0.25}, Link{Node: 3, Weight: 0.75}}
+
+	assert.Equal(Measure(links, []float64{1, 2, 3, 4}), 3.5, t)
+	assert.Equal(Measure(nil, []float64{1, 2, 3, 4}), 0.0, t)
+}
+
+func TestBuildSecondaryPackage(t *testing.T) {
+	blocks, specs, _ := Load(findFixture("002.flp"), findFixture("hotspot.config"),
+		"-model_secondary 1")
+
+	model, err := Build(blocks, specs)
+	assert.Equal(err, nil, t)
+
+	nb, nn := uint(len(blocks)), uint(len(model.Nodes))
+	p, q := 4*nb+peripheral, 9*nb+peripheral
+
+	sum := func(indices ...uint) float64 {
+		sum := 0.0
+		for _, i := range indices {
+			sum += model.C[i]
+		}
+		return sum
+	}
+
+	// The heat sink and the board are covered by their nodes exactly once.
+	s2, tk := specs.SinkSide, specs.SinkThickness
+	assert.Close(sum(3*nb, 3*nb+1, 4*nb+4, 4*nb+5, 4*nb+6, 4*nb+7, 4*nb+8, 4*nb+9,
+		4*nb+10, 4*nb+11), capacitanceFactor*(specs.SinkHeat*tk*s2*s2+
+		specs.ConvectionCapacitance), 1e-12, t)
+
+	sp, tp := specs.BoardSide, specs.BoardThickness
+	assert.Close(sum(p+4*nb, p+4*nb+1, q+8, q+9, q+10, q+11, q+12, q+13, q+14, q+15),
+		capacitanceFactor*(boardHeat*tp*sp*sp+specs.SecondaryConvectionCapacitance),
+		1e-12, t)
+
+	// The inner ring of the board spans the solder balls (r_pcb1_x).
+	so, w, h := specs.SolderSide, 0.004, 0.002
+	gx := boardConductivity * h * tp / (0.002 / 2)
+	gw := gx // core0 is the only block on the west edge.
+	r := (so - w) / 4 / (boardConductivity * (so + 3*h) / 4 * tp)
+	g := 2 / (1/gx + r*gw/gx)
+	assert.Close(model.G[(q+boardInnerWest)*nn+p+4*nb], -g/2, 1e-12, t)
+}
@@ -0,0 +1,202 @@
+package circuit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Kind is the kind of a thermal node.
+type Kind uint
+
+const (
+	Die        Kind = iota // a block of the die or of a layer of a stacked die
+	Interface              // a block of the thermal interface material
+	Spreader               // a block of the heat spreader
+	Sink                   // a block of the heat sink
+	Peripheral             // a peripheral part of the package
+	Metal                  // a block of the metal layers of the die
+	Bump                   // a block of the C4 pads and underfill
+	Substrate              // a block of the package substrate
+	Solder                 // a block of the solder balls
+	Board                  // a block of the printed circuit board
+)
+
+// Node is a thermal node of a circuit.
+type Node struct {
+	Name string
+	Kind Kind
+}
+
+const (
+	peripheral          = 12
+	secondaryPeripheral = 16
+)
+
+// Nodes returns the thermal nodes corresponding to a floorplan. The nodes are
+// ordered in the same way as in the thermal RC circuit; in particular, the
+// first len(blocks) nodes correspond to the blocks of the die.
+func Nodes(blocks []Block) []Node {
+	return nodes(blocks, []string{"", "iface_"}, []Kind{Die, Interface})
+}
+
+// stackNodes returns the thermal nodes corresponding to the cells of a stacked
+// die with a particular number of layers. The names of the nodes of the die
+// are prefixed with the numbers of their layers.
+func stackNodes(blocks []Block, nl uint) []Node {
+	prefixes, kinds := make([]string, nl), make([]Kind, nl)
+	for k := range prefixes {
+		prefixes[k], kinds[k] = fmt.Sprintf("layer%d_", k), Die
+	}
+	return nodes(blocks, prefixes, kinds)
+}
+
+func nodes(blocks []Block, prefixes []string, kinds []Kind) []Node {
+	nb, nl := len(blocks), len(prefixes)
+
+	nodes := make([]Node, (nl+2)*nb+peripheral)
+	for i, block := range blocks {
+		for k := range prefixes {
+			nodes[k*nb+i] = Node{Name: prefixes[k] + block.Name, Kind: kinds[k]}
+		}
+		nodes[nl*nb+i] = Node{Name: "hsp_" + block.Name, Kind: Spreader}
+		nodes[(nl+1)*nb+i] = Node{Name: "hsink_" + block.Name, Kind: Sink}
+	}
+	for i := 0; i < peripheral; i++ {
+		nodes[(nl+2)*nb+i] = Node{Name: fmt.Sprintf("inode_%d", i), Kind: Peripheral}
+	}
+
+	return nodes
+}
+
+// secondaryNodes returns the thermal nodes of the secondary heat path
+// corresponding to a floorplan, which follow the nodes given by Nodes.
+func secondaryNodes(blocks []Block) []Node {
+	nb := len(blocks)
+
+	nodes := make([]Node, 5*nb+secondaryPeripheral)
+	for i, block := range blocks {
+		nodes[0*nb+i] = Node{Name: "metal_" + block.Name, Kind: Metal}
+		nodes[1*nb+i] = Node{Name: "c4_" + block.Name, Kind: Bump}
+		nodes[2*nb+i] = Node{Name: "sub_" + block.Name, Kind: Substrate}
+		nodes[3*nb+i] = Node{Name: "solder_" + block.Name, Kind: Solder}
+		nodes[4*nb+i] = Node{Name: "pcb_" + block.Name, Kind: Board}
+	}
+	for i := 0; i < secondaryPeripheral; i++ {
+		nodes[5*nb+i] = Node{Name: fmt.Sprintf("inode_%d", peripheral+i), Kind: Peripheral}
+	}
+
+	return nodes
+}
+
+// LoadTemperature reads the temperature of thermal nodes from a file in the
+// format of HotSpot (init_file), which is also the format of the temperature
+// dumped by HotSpot. Each line of the file contains the name of a node followed
+// by its temperature in Kelvin. The file should give the temperature of each
+// of the nodes exactly once, and the result is ordered as the nodes.
+func LoadTemperature(path string, nodes []Node) ([]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseTemperature(file, path, nodes)
+}
+
+func parseTemperature(reader io.Reader, path string, nodes []Node) ([]float64, error) {
+	indices := make(map[string]int, len(nodes))
+	for i, node := range nodes {
+		indices[node.Name] = i
+	}
+
+	Q := make([]float64, len(nodes))
+	seen := make([]bool, len(nodes))
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a node name followed by a temperature",
+				path, line)
+		}
+		i, ok := indices[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: the node %q does not exist", path, line, fields[0])
+		}
+		if seen[i] {
+			return nil, fmt.Errorf("%s:%d: the node %q is given more than once",
+				path, line, fields[0])
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) || value <= 0 {
+			return nil, fmt.Errorf("%s:%d: the temperature should be finite and positive",
+				path, line)
+		}
+		Q[i], seen[i] = value, true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range seen {
+		if !seen[i] {
+			return nil, fmt.Errorf("%s: the temperature of the node %q is missing",
+				path, nodes[i].Name)
+		}
+	}
+
+	return Q, nil
+}
+
+// Initial returns the temperature of the thermal nodes at the beginning of a
+// simulation (see InitialTemperature and InitialFile in Specs). The file, if
+// any, is read on each call, and an error reading it is reported only here.
+func (self *Model) Initial() ([]float64, error) {
+	if len(self.initialFile) > 0 {
+		return LoadTemperature(self.initialFile, self.Nodes)
+	}
+	Q := make([]float64, len(self.Nodes))
+	for i := range Q {
+		Q[i] = self.initialTemperature
+	}
+	return Q, nil
+}
+
+// String returns the name of a kind.
+func (self Kind) String() string {
+	switch self {
+	case Die:
+		return "die"
+	case Interface:
+		return "interface"
+	case Spreader:
+		return "spreader"
+	case Sink:
+		return "sink"
+	case Peripheral:
+		return "peripheral"
+	case Metal:
+		return "metal"
+	case Bump:
+		return "bump"
+	case Substrate:
+		return "substrate"
+	case Solder:
+		return "solder"
+	case Board:
+		return "board"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,73 @@
+package circuit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestNodes(t *testing.T) {
+	nodes := Nodes([]Block{Block{Name: "core0"}, Block{Name: "core1"}})
+
+	assert.Equal(len(nodes), 4*2+12, t)
+
+	assert.Equal(nodes[0], Node{Name: "core0", Kind: Die}, t)
+	assert.Equal(nodes[1], Node{Name: "core1", Kind: Die}, t)
+	assert.Equal(nodes[2], Node{Name: "iface_core0", Kind: Interface}, t)
+	assert.Equal(nodes[5], Node{Name: "hsp_core1", Kind: Spreader}, t)
+	assert.Equal(nodes[7], Node{Name: "hsink_core1", Kind: Sink}, t)
+	assert.Equal(nodes[8], Node{Name: "inode_0", Kind: Peripheral}, t)
+	assert.Equal(nodes[19], Node{Name: "inode_11", Kind: Peripheral}, t)
+
+	nodes = secondaryNodes([]Block{Block{Name: "core0"}, Block{Name: "core1"}})
+
+	assert.Equal(len(nodes), 5*2+16, t)
+
+	assert.Equal(nodes[0], Node{Name: "metal_core0", Kind: Metal}, t)
+	assert.Equal(nodes[3], Node{Name: "c4_core1", Kind: Bump}, t)
+	assert.Equal(nodes[9], Node{Name: "pcb_core1", Kind: Board}, t)
+	assert.Equal(nodes[10], Node{Name: "inode_12", Kind: Peripheral}, t)
+	assert.Equal(nodes[25], Node{Name: "inode_27", Kind: Peripheral}, t)
+
+	assert.Equal(Sink.String(), "sink", t)
+	assert.Equal(Board.String(), "board", t)
+}
+
+func TestLoadTemperature(t *testing.T) {
+	nodes := Nodes([]Block{Block{Name: "core0"}, Block{Name: "core1"}})
+
+	Q, err := LoadTemperature(findFixture("002.init"), nodes)
+	assert.Equal(err, nil, t)
+	assert.Equal(len(Q), len(nodes), t)
+	assert.Equal(Q[:8], []float64{340.25, 338.5, 336.75, 336.5, 330, 330, 325.5, 325.5}, t)
+	assert.Equal(Q[19], 320.0, t)
+
+	_, err = LoadTemperature(findFixture("002.init"), nodes[:19])
+	assert.Equal(err != nil, true, t)
+
+	_, err = LoadTemperature(findFixture("missing.init"), nodes)
+	assert.Equal(err != nil, true, t)
+
+	nodes = nodes[:2]
+
+	Q, err = parseTemperature(strings.NewReader(`
+		# comment
+		core1	338.5
+		core0	340.25
+	`), "", nodes)
+	assert.Equal(err, nil, t)
+	assert.Equal(Q, []float64{340.25, 338.5}, t)
+
+	for _, content := range []string{
+		"core0 340\ncore0 340\ncore1 340",
+		"core0 340\ncore1 hot",
+		"core0 340\ncore1 -1",
+		"core0 340\ncore1",
+		"core0 340\ncore2 340",
+		"core0 340",
+	} {
+		_, err = parseTemperature(strings.NewReader(content), "", nodes)
+		assert.Equal(err != nil, true, t)
+	}
+}
@@ -0,0 +1,314 @@
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+)
+
+// Specs is a collection of the physical parameters of the thermal RC circuit.
+// The parameters correspond to those of HotSpot with the same meaning, which
+// are given in the comments.
+type Specs struct {
+	ChipThickness    float64 // t_chip, in meters
+	ChipConductivity float64 // k_chip, in W/(m K)
+	ChipHeat         float64 // p_chip, in J/(m^3 K)
+
+	InterfaceThickness    float64 // t_interface, in meters
+	InterfaceConductivity float64 // k_interface, in W/(m K)
+	InterfaceHeat         float64 // p_interface, in J/(m^3 K)
+
+	SpreaderSide         float64 // s_spreader, in meters
+	SpreaderThickness    float64 // t_spreader, in meters
+	SpreaderConductivity float64 // k_spreader, in W/(m K)
+	SpreaderHeat         float64 // p_spreader, in J/(m^3 K)
+
+	SinkSide         float64 // s_sink, in meters
+	SinkThickness    float64 // t_sink, in meters
+	SinkConductivity float64 // k_sink, in W/(m K)
+	SinkHeat         float64 // p_sink, in J/(m^3 K)
+
+	ConvectionResistance  float64 // r_convec, in K/W
+	ConvectionCapacitance float64 // c_convec, in J/K
+
+	// The flag for ignoring the lateral heat flow between the blocks of the
+	// die (block_omit_lateral).
+	OmitLateral bool
+
+	// The flag for modeling the secondary heat path from the die through the
+	// package and the printed circuit board to the ambience (model_secondary).
+	Secondary bool
+
+	SecondaryConvectionResistance  float64 // r_convec_sec, in K/W
+	SecondaryConvectionCapacitance float64 // c_convec_sec, in J/K
+
+	MetalLayers    uint    // n_metal
+	MetalThickness float64 // t_metal, in meters
+
+	BumpThickness float64 // t_c4, in meters
+	BumpSide      float64 // s_c4, in meters
+	BumpCount     uint    // n_c4
+
+	SubstrateSide      float64 // s_sub, in meters
+	SubstrateThickness float64 // t_sub, in meters
+
+	SolderSide      float64 // s_solder, in meters
+	SolderThickness float64 // t_solder, in meters
+
+	BoardSide      float64 // s_pcb, in meters
+	BoardThickness float64 // t_pcb, in meters
+
+	// The type of the model (model_type). Only the block model ("block") is
+	// supported; the grid model of HotSpot is not.
+	Model string
+
+	// The number of rows and columns of a grid dividing the die. If they are
+	// positive, the block model is applied to the cells of the grid instead of
+	// the blocks of the floorplan (see Build). This refinement is specific to
+	// this package and is not the grid model of HotSpot, so it is not set by
+	// the configuration of HotSpot. Each cell gives rise to at least four
+	// thermal nodes, and the matrices of the circuit are dense, so the memory
+	// needed grows with the square of the number of cells; for instance, a
+	// grid of 64×64 cells gives a conductance matrix of about 2 GB.
+	Rows    uint
+	Columns uint
+
+	// The way the temperature of a block is derived from the cells of the
+	// grid, which is either "avg" for the average over the cells covered by
+	// the block or "center" for the cell containing the center of the block.
+	Mapping string
+
+	// The temperature of the thermal nodes at the beginning of a simulation
+	// unless InitialFile is given (see Initial in Model).
+	InitialTemperature float64 // init_temp, in Kelvin
+
+	// The path to a file with the temperature of each thermal node at the
+	// beginning of a simulation (init_file; see LoadTemperature). The value
+	// "(null)" used by HotSpot is the same as an empty path. A relative path
+	// read by LoadSpecs is relative to the directory of the configuration
+	// file. The file is read only by Initial in Model.
+	InitialFile string
+}
+
+// DefaultSpecs returns the default parameters of HotSpot.
+func DefaultSpecs() *Specs {
+	return &Specs{
+		ChipThickness:    0.15e-3,
+		ChipConductivity: 100.0,
+		ChipHeat:         1.75e6,
+
+		InterfaceThickness:    20e-6,
+		InterfaceConductivity: 4.0,
+		InterfaceHeat:         4.0e6,
+
+		SpreaderSide:         0.03,
+		SpreaderThickness:    1e-3,
+		SpreaderConductivity: 400.0,
+		SpreaderHeat:         3.55e6,
+
+		SinkSide:         0.06,
+		SinkThickness:    6.9e-3,
+		SinkConductivity: 400.0,
+		SinkHeat:         3.55e6,
+
+		ConvectionResistance:  0.1,
+		ConvectionCapacitance: 140.4,
+
+		SecondaryConvectionResistance:  50.0,
+		SecondaryConvectionCapacitance: 40.0,
+
+		MetalLayers:    8,
+		MetalThickness: 100e-6,
+
+		BumpThickness: 0.1e-3,
+		BumpSide:      20e-6,
+		BumpCount:     400,
+
+		SubstrateSide:      0.021,
+		SubstrateThickness: 1e-3,
+
+		SolderSide:      0.021,
+		SolderThickness: 0.94e-3,
+
+		BoardSide:      0.1,
+		BoardThickness: 2e-3,
+
+		Model: "block",
+
+		Mapping: "center",
+
+		InitialTemperature: 333.15,
+	}
+}
+
+// LoadSpecs reads the parameters of a thermal model from a file in the format of
+// HotSpot (hotspot.config). The parameters that are not present in the file are
+// set to their default values (see DefaultSpecs), and the parameters that are
+// irrelevant to the thermal RC circuit are ignored.
+func LoadSpecs(path string) (*Specs, error) {
+	parameters, err := LoadConfiguration(path)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := DefaultSpecs()
+	if err := specs.Update(parameters); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(specs.InitialFile) > 0 && !filepath.IsAbs(specs.InitialFile) {
+		specs.InitialFile = filepath.Join(filepath.Dir(path), specs.InitialFile)
+	}
+
+	return specs, nil
+}
+
+// Update overwrites the parameters with the values given by their names in
+// HotSpot (see LoadConfiguration and ParseParameters). Unknown names are
+// ignored.
+func (self *Specs) Update(parameters map[string]string) error {
+	fields := map[string]*float64{
+		"t_chip": &self.ChipThickness,
+		"k_chip": &self.ChipConductivity,
+		"p_chip": &self.ChipHeat,
+
+		"t_interface": &self.InterfaceThickness,
+		"k_interface": &self.InterfaceConductivity,
+		"p_interface": &self.InterfaceHeat,
+
+		"s_spreader": &self.SpreaderSide,
+		"t_spreader": &self.SpreaderThickness,
+		"k_spreader": &self.SpreaderConductivity,
+		"p_spreader": &self.SpreaderHeat,
+
+		"s_sink": &self.SinkSide,
+		"t_sink": &self.SinkThickness,
+		"k_sink": &self.SinkConductivity,
+		"p_sink": &self.SinkHeat,
+
+		"r_convec": &self.ConvectionResistance,
+		"c_convec": &self.ConvectionCapacitance,
+
+		"r_convec_sec": &self.SecondaryConvectionResistance,
+		"c_convec_sec": &self.SecondaryConvectionCapacitance,
+
+		"t_metal":  &self.MetalThickness,
+		"t_c4":     &self.BumpThickness,
+		"s_c4":     &self.BumpSide,
+		"s_sub":    &self.SubstrateSide,
+		"t_sub":    &self.SubstrateThickness,
+		"s_solder": &self.SolderSide,
+		"t_solder": &self.SolderThickness,
+		"s_pcb":    &self.BoardSide,
+		"t_pcb":    &self.BoardThickness,
+
+		"init_temp": &self.InitialTemperature,
+	}
+	counts := map[string]*uint{
+		"n_metal": &self.MetalLayers,
+		"n_c4":    &self.BumpCount,
+	}
+	flags := map[string]*bool{
+		"block_omit_lateral": &self.OmitLateral,
+		"model_secondary":    &self.Secondary,
+	}
+	words := map[string]*string{
+		"model_type": &self.Model,
+
+		"init_file": &self.InitialFile,
+	}
+
+	for name, value := range parameters {
+		if field, ok := words[name]; ok {
+			if name == "init_file" && value == "(null)" {
+				value = ""
+			}
+			*field = value
+			continue
+		}
+		if field, ok := counts[name]; ok {
+			number, err := strconv.ParseUint(value, 10, 0)
+			if err != nil {
+				return fmt.Errorf("the parameter %q should be a nonnegative integer", name)
+			}
+			*field = uint(number)
+			continue
+		}
+		if field, ok := flags[name]; ok {
+			flag, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("the parameter %q should be an integer", name)
+			}
+			*field = flag != 0
+			continue
+		}
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("the parameter %q should be a number", name)
+		}
+		*field = number
+	}
+
+	return nil
+}
+
+func (self *Specs) check() error {
+	values := []float64{
+		self.ChipThickness, self.ChipConductivity, self.ChipHeat,
+		self.InterfaceThickness, self.InterfaceConductivity, self.InterfaceHeat,
+		self.SpreaderSide, self.SpreaderThickness, self.SpreaderConductivity, self.SpreaderHeat,
+		self.SinkSide, self.SinkThickness, self.SinkConductivity, self.SinkHeat,
+		self.ConvectionResistance, self.ConvectionCapacitance,
+	}
+	for _, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) || value <= 0 {
+			return errors.New("the parameters of the thermal model should be positive")
+		}
+	}
+	if value := self.InitialTemperature; math.IsNaN(value) || math.IsInf(value, 0) || value <= 0 {
+		return errors.New("the initial temperature should be finite and positive")
+	}
+	if self.SpreaderSide > self.SinkSide {
+		return errors.New("the heat spreader should not be larger than the heat sink")
+	}
+	if self.Secondary {
+		values := []float64{
+			self.SecondaryConvectionResistance, self.SecondaryConvectionCapacitance,
+			float64(self.MetalLayers), self.MetalThickness,
+			self.BumpThickness, self.BumpSide, float64(self.BumpCount),
+			self.SubstrateSide, self.SubstrateThickness,
+			self.SolderSide, self.SolderThickness,
+			self.BoardSide, self.BoardThickness,
+		}
+		for _, value := range values {
+			if math.IsNaN(value) || math.IsInf(value, 0) || value <= 0 {
+				return errors.New("the parameters of the secondary heat path should be positive")
+			}
+		}
+		if self.SolderSide > self.BoardSide {
+			return errors.New("the solder balls should not be larger than the circuit board")
+		}
+	}
+	if self.Model != "" && self.Model != "block" {
+		return fmt.Errorf("the model type %q is not supported", self.Model)
+	}
+	if self.grid() {
+		if self.Rows == 0 || self.Columns == 0 {
+			return errors.New("the grid should have at least one row and one column")
+		}
+		if self.Mapping != "avg" && self.Mapping != "center" {
+			return fmt.Errorf("the grid mapping mode %q is not supported", self.Mapping)
+		}
+	}
+	return nil
+}
+
+// grid checks if the die is divided into a grid of cells (see Rows and Columns).
+func (self *Specs) grid() bool {
+	return self.Rows > 0 || self.Columns > 0
+}
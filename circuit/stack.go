@@ -0,0 +1,113 @@
+package circuit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Layer is a layer of a stacked die, such as a layer of silicon or of thermal
+// interface material, with its own floorplan.
+type Layer struct {
+	// The floorplan of the layer.
+	Blocks []Block
+
+	Thickness    float64 // in meters
+	Conductivity float64 // in W/(m K)
+	Heat         float64 // in J/(m^3 K)
+
+	// The flag for modeling the lateral heat flow within the layer.
+	Lateral bool
+
+	// The flag for the dissipation of power in the layer. Only the blocks of
+	// such layers dissipate power and can be selected as sources and targets.
+	Power bool
+}
+
+// LoadLayers reads the layers of a stacked die from a layer configuration file
+// in the format of HotSpot (.lcf). The layers are listed starting from the one
+// farthest from the heat spreader. Each layer is described by seven values on
+// separate lines: the number of the layer, the lateral-heat-flow flag (Y or N),
+// the power-dissipation flag (Y or N), the specific heat in J/(m^3 K), the
+// resistivity in (m K)/W, the thickness in meters, and the path to the
+// floorplan, which is resolved relative to the file. Blank lines and comments
+// are skipped.
+func LoadLayers(path string) ([]Layer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := []string{}
+	lines := []int{}
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		values = append(values, fields[0])
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, errors.New("the layer configuration should contain at least one layer")
+	}
+	if len(values)%7 != 0 {
+		return nil, fmt.Errorf("%s: expected seven values per layer", path)
+	}
+
+	layers := make([]Layer, len(values)/7)
+	for i := range layers {
+		layer, values, lines := &layers[i], values[7*i:], lines[7*i:]
+
+		number, err := strconv.Atoi(values[0])
+		if err != nil || number != i {
+			return nil, fmt.Errorf("%s:%d: expected the layer number %d", path, lines[0], i)
+		}
+
+		flags := []*bool{&layer.Lateral, &layer.Power}
+		for j, flag := range flags {
+			switch strings.ToUpper(values[j+1]) {
+			case "Y":
+				*flag = true
+			case "N":
+				*flag = false
+			default:
+				return nil, fmt.Errorf("%s:%d: expected Y or N", path, lines[j+1])
+			}
+		}
+
+		numbers := make([]float64, 3)
+		for j := range numbers {
+			if numbers[j], err = strconv.ParseFloat(values[j+3], 64); err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, lines[j+3], err)
+			}
+		}
+		layer.Heat, layer.Thickness = numbers[0], numbers[2]
+		layer.Conductivity = 1 / numbers[1]
+
+		floorplan := values[6]
+		if !filepath.IsAbs(floorplan) {
+			floorplan = filepath.Join(filepath.Dir(path), floorplan)
+		}
+		if layer.Blocks, err = LoadFloorplan(floorplan); err != nil {
+			return nil, err
+		}
+	}
+
+	return layers, nil
+}
@@ -0,0 +1,50 @@
+package circuit
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestLoadLayers(t *testing.T) {
+	layers, err := LoadLayers(findFixture("003.lcf"))
+
+	assert.Equal(err, nil, t)
+	assert.Equal(len(layers), 4, t)
+
+	assert.Equal(layers[0].Blocks[1].Name, "bank1", t)
+	assert.Equal(layers[0].Thickness, 0.00005, t)
+	assert.Equal(layers[0].Conductivity, 100.0, t)
+	assert.Equal(layers[0].Heat, 1.75e6, t)
+	assert.Equal(layers[0].Lateral, true, t)
+	assert.Equal(layers[0].Power, true, t)
+
+	assert.Equal(layers[1].Lateral, false, t)
+	assert.Equal(layers[1].Power, false, t)
+	assert.Equal(layers[1].Conductivity, 4.0, t)
+
+	assert.Equal(layers[2].Blocks[0].Name, "core0", t)
+	assert.Equal(layers[2].Power, true, t)
+}
+
+func TestLoadLayersInvalid(t *testing.T) {
+	name := path.Join(findFixture(""), "circuit_invalid.lcf")
+	defer os.Remove(name)
+
+	cases := []string{
+		"",
+		"0\nY\nY\n1.75e6\n0.01\n0.00015",
+		"1\nY\nY\n1.75e6\n0.01\n0.00015\n002.flp",
+		"0\nY\nX\n1.75e6\n0.01\n0.00015\n002.flp",
+		"0\nY\nY\n1.75e6\nhigh\n0.00015\n002.flp",
+		"0\nY\nY\n1.75e6\n0.01\n0.00015\nmissing.flp",
+	}
+	for _, content := range cases {
+		assert.Equal(ioutil.WriteFile(name, []byte(content), 0644), nil, t)
+		_, err := LoadLayers(name)
+		assert.Equal(err != nil, true, t)
+	}
+}
@@ -0,0 +1,106 @@
+// Package check provides validation of the input of the integrators, which is
+// shared by the analytic and numeric packages.
+package check
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Power checks that a power profile consists of finite values and of whole
+// samples of a particular number of sources and returns the number of
+// samples.
+func Power(P []float64, nc uint) (uint, error) {
+	if uint(len(P))%nc != 0 {
+		return 0, fmt.Errorf("the number of power values (%d) should be a multiple "+
+			"of the number of sources (%d)", len(P), nc)
+	}
+	for i, p := range P {
+		if !Finite(p) {
+			return 0, fmt.Errorf("the power of source %d at sample %d should be finite",
+				uint(i)%nc, uint(i)/nc)
+		}
+	}
+	return uint(len(P)) / nc, nil
+}
+
+// Duration checks that the durations of the samples of a power profile are
+// finite and nonnegative and that there is one per sample.
+func Duration(ΔT []float64, ns uint) error {
+	if uint(len(ΔT)) != ns {
+		return fmt.Errorf("the number of durations (%d) should be equal to "+
+			"the number of power samples (%d)", len(ΔT), ns)
+	}
+	for i, Δt := range ΔT {
+		if !Finite(Δt) || Δt < 0 {
+			return fmt.Errorf("the duration of sample %d should be finite and nonnegative", i)
+		}
+	}
+	return nil
+}
+
+// Time checks that time moments are nondecreasing and lie within a power
+// profile of a particular total duration.
+func Time(T []float64, total float64) error {
+	for i, t := range T {
+		if !Finite(t) || t < 0 || t > total || i > 0 && t < T[i-1] {
+			return fmt.Errorf("the time moment %d should be within the power profile "+
+				"and not precede the previous one", i)
+		}
+	}
+	return nil
+}
+
+// Tolerance checks that a tolerance is finite and positive.
+func Tolerance(ε float64) error {
+	if !Finite(ε) || ε <= 0 {
+		return errors.New("the tolerance should be finite and positive")
+	}
+	return nil
+}
+
+// Temperature checks that a vector contains one finite temperature per
+// thermal node.
+func Temperature(Q []float64, nn uint) error {
+	if uint(len(Q)) != nn {
+		return fmt.Errorf("the temperature should have %d elements, one per thermal node", nn)
+	}
+	for i, q := range Q {
+		if !Finite(q) {
+			return fmt.Errorf("the temperature of node %d should be finite", i)
+		}
+	}
+	return nil
+}
+
+// State checks that a state of a system has a particular number of elements
+// unless it is nil.
+func State(S []float64, nn uint) error {
+	if S != nil && uint(len(S)) != nn {
+		return fmt.Errorf("the state should have %d elements", nn)
+	}
+	return nil
+}
+
+// Sample checks that a power sample has one finite value per source unless it
+// is nil.
+func Sample(P []float64, nc uint) error {
+	if P == nil {
+		return nil
+	}
+	if uint(len(P)) != nc {
+		return fmt.Errorf("the power sample should have %d elements, one per source", nc)
+	}
+	for k, p := range P {
+		if !Finite(p) {
+			return fmt.Errorf("the power of source %d should be finite", k)
+		}
+	}
+	return nil
+}
+
+// Finite checks if a value is neither infinite nor NaN.
+func Finite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
@@ -0,0 +1,82 @@
+// Package network provides the construction of the thermal RC circuit
+// connected to the sources and targets, which is shared by the analytic and
+// numeric packages.
+package network
+
+import (
+	"errors"
+
+	"github.com/turing-complete/temperature/circuit"
+	"github.com/turing-complete/temperature/internal/check"
+)
+
+// Network is a thermal RC circuit connected to the blocks dissipating power
+// and to the blocks whose temperature is observed.
+type Network struct {
+	*circuit.Model
+
+	// The blocks dissipating power.
+	Sources []circuit.Block
+
+	// The blocks whose temperature is observed, which is nil when all nodes
+	// are observed.
+	Targets []circuit.Block
+
+	// The thermal nodes dissipating the power of each source.
+	In [][]circuit.Link
+
+	// The thermal nodes determining the temperature of each target or each
+	// thermal node itself when all nodes are observed.
+	Out [][]circuit.Link
+
+	// The thermal nodes determining the temperature of each source.
+	Probe [][]circuit.Link
+}
+
+// New constructs the thermal RC circuit specified by a configuration and
+// connects it to the sources and targets given by their names (see Select in
+// circuit). If all is true, each thermal node is observed instead of the
+// targets, which should then be empty. The ambient temperature qamb is only
+// validated.
+func New(config *circuit.Config, qamb float64, sources, targets []string,
+	all bool) (*Network, error) {
+
+	if !check.Finite(qamb) || qamb <= 0 {
+		return nil, errors.New("the ambient temperature should be finite and positive")
+	}
+
+	if all && len(targets) > 0 {
+		return nil, errors.New("the targets should be empty when all nodes are observed")
+	}
+
+	model, err := circuit.New(config)
+	if err != nil {
+		return nil, err
+	}
+	network := &Network{Model: model}
+
+	var indices []uint
+
+	network.Sources, indices, err = circuit.Select(model.Blocks, sources)
+	if err != nil {
+		return nil, err
+	}
+	network.In = circuit.Links(model.Inputs, indices)
+	network.Probe = circuit.Links(model.Outputs, indices)
+
+	if all {
+		network.Out = make([][]circuit.Link, len(model.Nodes))
+		for i := range network.Out {
+			network.Out[i] = []circuit.Link{circuit.Link{Node: uint(i), Weight: 1}}
+		}
+		return network, nil
+	}
+
+	network.Targets, indices, err = circuit.Select(model.Blocks, targets)
+	if err != nil {
+		return nil, err
+	}
+	network.Out = circuit.Links(model.Outputs, indices)
+
+	return network, nil
+}
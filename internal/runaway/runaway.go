@@ -0,0 +1,203 @@
+// Package runaway provides the detection of thermal runaway, which is shared
+// by the analytic and numeric packages.
+package runaway
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/turing-complete/temperature/circuit"
+	"github.com/turing-complete/temperature/internal/check"
+)
+
+const (
+	// The temperature increment used to estimate the sensitivity of the static
+	// power to temperature.
+	δ = 1e-3 // in Kelvin
+
+	// The maximal number of iterations of the power method.
+	iterations = 100
+)
+
+// Error is an error signaling thermal runaway, which is detected when the
+// temperature of a block dissipating power exceeds the ceiling or when either
+// the temperature or the static power of the block ceases to be finite. It is
+// also detected when the static power grows faster with temperature than the
+// heat can be dissipated and the temperature keeps rising, in which case there
+// is no fixed point of the temperature–leakage loop ahead.
+type Error struct {
+	Sample uint   // the index of the sample
+	Core   uint   // the index of the block in Sources
+	Name   string // the name of the block
+
+	Temperature float64 // in Kelvin
+	Power       float64 // in Watts
+}
+
+// Error returns a description of the error.
+func (self *Error) Error() string {
+	return fmt.Sprintf("thermal runaway in block %q at sample %d (%g K, %g W)",
+		self.Name, self.Sample, self.Temperature, self.Power)
+}
+
+// Detector is a detector of thermal runaway.
+type Detector struct {
+	nc uint
+
+	// The steady-state temperature rise of each source per unit power of each
+	// source, which is an nc×nc matrix whose column j corresponds to source j.
+	R []float64
+
+	qamb float64
+	qmax float64
+
+	sources []circuit.Block
+
+	L []float64
+	P []float64
+	g []float64
+	w []float64
+	x []float64
+}
+
+// New returns a new detector. The steady-state resistance between the sources
+// is specified by R (see Detector), and qmax is the ceiling on the temperature
+// of the sources; if zero, there is no ceiling.
+func New(R []float64, qamb, qmax float64, sources []circuit.Block) *Detector {
+	nc := uint(len(sources))
+	return &Detector{
+		nc: nc,
+
+		R: R,
+
+		qamb: qamb,
+		qmax: qmax,
+
+		sources: sources,
+
+		L: make([]float64, nc),
+		P: make([]float64, nc),
+		g: make([]float64, nc),
+		w: make([]float64, nc),
+		x: make([]float64, nc),
+	}
+}
+
+// Check checks the temperature Q of the sources at a sample. If P is not nil,
+// it is the total power of the sources, which is checked as well along with
+// the existence of a fixed point of the loop between temperature and the
+// static power given by leak, which is called twice with temperature near Q.
+func (self *Detector) Check(sample uint, Q, P []float64,
+	leak func([]float64, []float64)) error {
+
+	for k := range Q {
+		runaway := !check.Finite(Q[k]) || self.qmax > 0 && Q[k] > self.qmax
+		if P != nil {
+			runaway = runaway || !check.Finite(P[k])
+		}
+		if runaway {
+			return self.error(sample, uint(k), Q, P)
+		}
+	}
+	if P == nil {
+		return nil
+	}
+	if k, ok := self.diverge(Q, P, leak); ok {
+		return self.error(sample, k, Q, P)
+	}
+	return nil
+}
+
+// diverge checks if the temperature Q of the sources is driven away from any
+// fixed point of the loop between temperature and static power given the
+// total power P. The loop is linearized at Q, which gives the gain M = R *
+// diag(g) where g is the derivative of the static power with respect to
+// temperature. Divergence requires the spectral radius of M to be at least
+// one and the steady-state temperature to lie above Q along the left Perron
+// vector of M. In that case, the source with the largest component of the
+// vector is returned.
+func (self *Detector) diverge(Q, P []float64, leak func([]float64, []float64)) (uint, bool) {
+	nc, R, L, Ps, g, w, x := self.nc, self.R, self.L, self.P, self.g, self.w, self.x
+
+	for k := uint(0); k < nc; k++ {
+		g[k], Ps[k], L[k] = 0, 0, Q[k]+δ
+	}
+	leak(Q, g)
+	leak(L, Ps)
+	for k := uint(0); k < nc; k++ {
+		g[k] = math.Max(0, (Ps[k]-g[k])/δ)
+	}
+
+	// The largest row sum of M bounds its spectral radius.
+	bound := 0.0
+	for k := uint(0); k < nc; k++ {
+		sum := 0.0
+		for j := uint(0); j < nc; j++ {
+			sum += R[j*nc+k] * g[j]
+		}
+		bound = math.Max(bound, sum)
+	}
+	if bound < 1 {
+		return 0, false
+	}
+
+	// The power method with the bounds of Collatz and Wielandt.
+	for k := uint(0); k < nc; k++ {
+		w[k] = 1
+	}
+	found := false
+	for l := 0; l < iterations && !found; l++ {
+		lower, upper, norm := math.Inf(1), 0.0, 0.0
+		for j := uint(0); j < nc; j++ {
+			x[j] = 0
+			for k := uint(0); k < nc; k++ {
+				x[j] += w[k] * R[j*nc+k]
+			}
+			x[j] *= g[j]
+			if w[j] > 0 {
+				lower = math.Min(lower, x[j]/w[j])
+				upper = math.Max(upper, x[j]/w[j])
+			}
+			norm = math.Max(norm, x[j])
+		}
+		if upper < 1 || norm == 0 {
+			return 0, false
+		}
+		found = lower >= 1
+		for j := uint(0); j < nc; j++ {
+			w[j] = x[j] / norm
+		}
+	}
+	if !found {
+		return 0, false
+	}
+
+	drift, core := 0.0, uint(0)
+	for k := uint(0); k < nc; k++ {
+		Δ := self.qamb - Q[k]
+		for j := uint(0); j < nc; j++ {
+			Δ += R[j*nc+k] * P[j]
+		}
+		drift += w[k] * Δ
+		if w[k] > w[core] {
+			core = k
+		}
+	}
+
+	return core, drift > 0
+}
+
+func (self *Detector) error(sample, k uint, Q, P []float64) error {
+	err := &Error{
+		Sample: sample,
+		Core:   k,
+		Name:   self.sources[k].Name,
+
+		Temperature: Q[k],
+		Power:       math.NaN(),
+	}
+	if P != nil {
+		err.Power = P[k]
+	}
+	return err
+}
@@ -0,0 +1,11 @@
+// Package leakage provides models of the static power of processing elements
+// as a function of their temperature.
+//
+// Each model has a method Compute(Q, P []float64) that adds the static power
+// corresponding to the temperature Q to the power P. The method matches the
+// leak argument of the ComputeWithStatic methods of the integrators in the
+// analytic and numeric packages; for instance,
+//
+//     model := &leakage.Exponential{Power: 1, Rate: 0.02, Reference: 318.15}
+//     Q, _, err := integrator.ComputeWithStatic(P, nil, model.Compute)
+package leakage
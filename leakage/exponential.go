@@ -0,0 +1,22 @@
+package leakage
+
+import (
+	"math"
+)
+
+// Exponential is a model of the static power that is exponential in
+// temperature:
+//
+//     P = Power * exp(Rate * (Q - Reference)).
+type Exponential struct {
+	Power     float64 // in Watts
+	Rate      float64 // in 1/Kelvin
+	Reference float64 // in Kelvin
+}
+
+// Compute adds the static power corresponding to a temperature Q to a power P.
+func (self *Exponential) Compute(Q, P []float64) {
+	for i := range P {
+		P[i] += self.Power * math.Exp(self.Rate*(Q[i]-self.Reference))
+	}
+}
@@ -0,0 +1,17 @@
+package leakage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestExponentialCompute(t *testing.T) {
+	model := &Exponential{Power: 2, Rate: 0.01, Reference: 300}
+
+	P := []float64{1, 1}
+	model.Compute([]float64{300, 400}, P)
+
+	assert.Close(P, []float64{3, 1 + 2*math.E}, 1e-14, t)
+}
@@ -0,0 +1,21 @@
+package leakage
+
+// Linear is a model of the static power that is linear in temperature:
+//
+//     P = Power + Slope * (Q - Reference).
+//
+// The static power is not allowed to become negative.
+type Linear struct {
+	Power     float64 // in Watts
+	Slope     float64 // in Watts per Kelvin
+	Reference float64 // in Kelvin
+}
+
+// Compute adds the static power corresponding to a temperature Q to a power P.
+func (self *Linear) Compute(Q, P []float64) {
+	for i := range P {
+		if p := self.Power + self.Slope*(Q[i]-self.Reference); p > 0 {
+			P[i] += p
+		}
+	}
+}
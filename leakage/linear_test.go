@@ -0,0 +1,16 @@
+package leakage
+
+import (
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestLinearCompute(t *testing.T) {
+	model := &Linear{Power: 2, Slope: 0.1, Reference: 300}
+
+	P := []float64{1, 1, 1}
+	model.Compute([]float64{300, 310, 200}, P)
+
+	assert.Close(P, []float64{3, 4, 1}, 1e-14, t)
+}
@@ -0,0 +1,48 @@
+package leakage
+
+import (
+	"fmt"
+	"math"
+)
+
+// The Boltzmann constant divided by the elementary charge.
+const thermalVoltage = 8.617333262e-5 // in Volts per Kelvin
+
+// Subthreshold is a BSIM-style model of the static power due to the
+// subthreshold leakage current:
+//
+//     P = Voltage * Scale * Q**2 * exp(-Vth / (Slope * Vt)) *
+//         (1 - exp(-Voltage / Vt))
+//
+// where Vt = k * Q / q is the thermal voltage, and
+//
+//     Vth = Threshold - Drift * (Q - Reference)
+//
+// is the threshold voltage. Scale is given per processing element and lumps
+// together the mobility, oxide capacitance, geometry, and number of
+// transistors; Compute panics if there are fewer values in Scale than
+// processing elements.
+type Subthreshold struct {
+	Voltage   float64 // in Volts
+	Threshold float64 // in Volts
+	Slope     float64 // dimensionless
+	Drift     float64 // in Volts per Kelvin
+	Reference float64 // in Kelvin
+
+	Scale []float64 // in Amperes per Kelvin squared
+}
+
+// Compute adds the static power corresponding to a temperature Q to a power P.
+func (self *Subthreshold) Compute(Q, P []float64) {
+	if len(self.Scale) < len(P) {
+		panic(fmt.Sprintf("the number of scale values (%d) should be at least "+
+			"the number of processing elements (%d)", len(self.Scale), len(P)))
+	}
+	for i := range P {
+		vt := thermalVoltage * Q[i]
+		vth := self.Threshold - self.Drift*(Q[i]-self.Reference)
+		current := self.Scale[i] * Q[i] * Q[i] * math.Exp(-vth/(self.Slope*vt)) *
+			(1 - math.Exp(-self.Voltage/vt))
+		P[i] += self.Voltage * current
+	}
+}
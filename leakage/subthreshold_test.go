@@ -0,0 +1,48 @@
+package leakage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestSubthresholdCompute(t *testing.T) {
+	model := &Subthreshold{
+		Voltage:   1.0,
+		Threshold: 0.3,
+		Slope:     1.5,
+		Drift:     1e-3,
+		Reference: 300,
+
+		Scale: []float64{1e-6, 2e-6},
+	}
+
+	Q := []float64{300, 300}
+	P := []float64{0, 0}
+	model.Compute(Q, P)
+
+	vt := thermalVoltage * 300
+	p := 1e-6 * 300 * 300 * math.Exp(-0.3/(1.5*vt)) * (1 - math.Exp(-1/vt))
+	assert.Close(P, []float64{p, 2 * p}, 1e-14, t)
+
+	Q = []float64{350, 350}
+	P = []float64{0, 0}
+	model.Compute(Q, P)
+
+	if P[0] <= p {
+		t.Fatalf("the static power should grow with temperature")
+	}
+}
+
+func TestSubthresholdComputeShort(t *testing.T) {
+	model := &Subthreshold{Voltage: 1, Slope: 1.5, Scale: []float64{1e-6}}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("the model should panic when Scale is short")
+		}
+	}()
+
+	model.Compute([]float64{300, 300}, []float64{0, 0})
+}
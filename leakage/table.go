@@ -0,0 +1,64 @@
+package leakage
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/turing-complete/temperature/internal/check"
+)
+
+// Table is a model of the static power given by a piecewise-linear lookup
+// table. Outside the range of the table, the static power is extrapolated
+// using the first or the last segment. The static power is not allowed to
+// become negative.
+type Table struct {
+	temperature []float64
+	power       []float64
+}
+
+// NewTable returns a new lookup table. The temperature points, given in
+// Kelvin, should be strictly increasing; the power points are given in Watts.
+// All the points should be finite.
+func NewTable(temperature, power []float64) (*Table, error) {
+	if len(temperature) < 2 {
+		return nil, errors.New("the table should have at least two points")
+	}
+	if len(temperature) != len(power) {
+		return nil, errors.New("the numbers of temperature and power points should match")
+	}
+	for i := range temperature {
+		if !check.Finite(temperature[i]) || !check.Finite(power[i]) {
+			return nil, errors.New("the temperature and power points should be finite")
+		}
+	}
+	for i := 1; i < len(temperature); i++ {
+		if temperature[i] <= temperature[i-1] {
+			return nil, errors.New("the temperature points should be strictly increasing")
+		}
+	}
+
+	table := &Table{
+		temperature: append([]float64(nil), temperature...),
+		power:       append([]float64(nil), power...),
+	}
+
+	return table, nil
+}
+
+// Compute adds the static power corresponding to a temperature Q to a power P.
+func (self *Table) Compute(Q, P []float64) {
+	T, W := self.temperature, self.power
+	n := len(T)
+
+	for i := range P {
+		j := sort.SearchFloat64s(T, Q[i])
+		if j == 0 {
+			j = 1
+		} else if j == n {
+			j = n - 1
+		}
+		if p := W[j-1] + (W[j]-W[j-1])*(Q[i]-T[j-1])/(T[j]-T[j-1]); p > 0 {
+			P[i] += p
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package leakage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ready-steady/assert"
+)
+
+func TestNewTable(t *testing.T) {
+	_, err := NewTable([]float64{300}, []float64{1})
+	assert.Equal(err != nil, true, t)
+
+	_, err = NewTable([]float64{300, 310}, []float64{1})
+	assert.Equal(err != nil, true, t)
+
+	_, err = NewTable([]float64{310, 300}, []float64{1, 2})
+	assert.Equal(err != nil, true, t)
+
+	_, err = NewTable([]float64{300, math.NaN()}, []float64{1, 2})
+	assert.Equal(err != nil, true, t)
+
+	_, err = NewTable([]float64{300, 310}, []float64{1, math.Inf(1)})
+	assert.Equal(err != nil, true, t)
+}
+
+func TestTableCompute(t *testing.T) {
+	model, _ := NewTable([]float64{300, 320, 340}, []float64{1, 2, 4})
+
+	P := []float64{0, 0, 0, 0, 0, 0}
+	model.Compute([]float64{290, 300, 310, 320, 330, 350}, P)
+
+	assert.Close(P, []float64{0.5, 1, 1.5, 2, 3, 5}, 1e-14, t)
+
+	P = []float64{1, 1, 1}
+	model.Compute([]float64{270, 290, 250}, P)
+
+	assert.Close(P, []float64{1, 1.5, 1}, 1e-14, t)
+}
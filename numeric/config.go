@@ -1,14 +1,33 @@
 package numeric
 
 import (
-	"github.com/turing-complete/hotspot"
+	"github.com/turing-complete/temperature/circuit"
 )
 
 // Config is a configuration of temperature analysis.
 type Config struct {
-	// The thermal RC model.
-	hotspot.Config
+	// The thermal RC circuit.
+	circuit.Config
 
 	// The ambient temperature.
 	Ambience float64 // in Kelvin
+
+	// The names of the blocks dissipating power, which correspond to the rows
+	// of power profiles. If empty, all the blocks of the floorplan are taken.
+	Sources []string
+
+	// The names of the blocks whose temperature is observed, which correspond
+	// to the rows of temperature profiles. If empty, all the blocks of the
+	// floorplan are taken.
+	Targets []string
+
+	// The flag for observing all thermal nodes instead of Targets (see Nodes
+	// in Temperature).
+	AllNodes bool
+
+	// The temperature above which thermal runaway is reported when static
+	// power is taken into account. If zero, there is no ceiling, and runaway
+	// is still reported when the temperature–leakage loop has no fixed point
+	// (see RunawayError).
+	Ceiling float64 // in Kelvin
 }
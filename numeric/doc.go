@@ -29,4 +29,9 @@
 //
 //     A = -C**(-1) * G and
 //     B = C**(-1) * M.
+//
+// Assuming that the power dissipation is constant, the steady-state solution
+// to the system is obtained by solving
+//
+//     G * S = M * P.
 package numeric
@@ -8058,3 +8058,8 @@ var fixtureTime = []float64{
 	4.3966166014763308e-01, 4.3974260245029312e-01, 4.3982512147218039e-01,
 	4.3991190277921771e-01, 4.4000000000000000e-01,
 }
+
+var fixtureQSteady = []float64{
+	+3.4460360503230710e+02, +3.6323923489247010e+02,
+	+3.5131542823834050e+02, +3.3267979837817760e+02,
+}
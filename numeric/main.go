@@ -2,22 +2,61 @@ package numeric
 
 import (
 	"github.com/ready-steady/ode"
-	"github.com/turing-complete/hotspot"
+	"github.com/turing-complete/temperature/circuit"
+	"github.com/turing-complete/temperature/internal/network"
 )
 
 // Temperature is an integrator of a thermal system.
 type Temperature struct {
 	nc uint
 	nn uint
+	no uint
+
+	in    [][]circuit.Link
+	out   [][]circuit.Link
+	probe [][]circuit.Link
+
+	// The blocks dissipating power, which correspond to the rows of power
+	// profiles.
+	Sources []circuit.Block
+
+	// The blocks whose temperature is observed, which correspond to the rows
+	// of temperature profiles unless all nodes are observed (see Config).
+	Targets []circuit.Block
+
+	// The thermal nodes of the system.
+	Nodes []circuit.Node
 
 	system     system
 	integrator ode.Integrator
+
+	// The Cholesky factor of the conductance matrix (see factorize), which is
+	// used by SteadyState.
+	factor []float64
+
+	// The steady-state temperature rise of the sources per unit power of the
+	// sources, which is used to detect thermal runaway (see resistance).
+	resistance []float64
+
+	qmax float64
+
+	// The initial temperature of the thermal nodes (see Initial).
+	initial func() ([]float64, error)
 }
 
 // New returns a new integrator.
-func New(config *Config, integrator ode.Integrator) *Temperature {
-	model := hotspot.New((*hotspot.Config)(&config.Config))
-	nc, nn := model.Cores, model.Nodes
+func New(config *Config, integrator ode.Integrator) (*Temperature, error) {
+	model, err := network.New(&config.Config, config.Ambience, config.Sources,
+		config.Targets, config.AllNodes)
+	if err != nil {
+		return nil, err
+	}
+	in, out, probe := model.In, model.Out, model.Probe
+
+	nodes := model.Nodes
+	nn := uint(len(nodes))
+
+	nc, no := uint(len(in)), uint(len(out))
 
 	A := model.G // Reuse model.G to store A.
 	B := model.C // Reuse model.C to store B.
@@ -30,17 +69,41 @@ func New(config *Config, integrator ode.Integrator) *Temperature {
 		}
 	}
 
-	return &Temperature{
+	system := system{
+		A: A,
+		B: B,
+
+		Qamb: config.Ambience,
+	}
+
+	factor, err := conductance(&system, nn)
+	if err != nil {
+		return nil, err
+	}
+
+	temperature := &Temperature{
 		nc: nc,
 		nn: nn,
+		no: no,
 
-		system: system{
-			A: A,
-			B: B,
+		in:    in,
+		out:   out,
+		probe: probe,
 
-			Qamb: config.Ambience,
-		},
+		Sources: model.Sources,
+		Targets: model.Targets,
+		Nodes:   nodes,
 
+		system:     system,
 		integrator: integrator,
+
+		factor:     factor,
+		resistance: resistance(factor, in, probe, nn),
+
+		qmax: config.Ceiling,
+
+		initial: model.Initial,
 	}
+
+	return temperature, nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/ready-steady/assert"
 	"github.com/ready-steady/fixture"
 	"github.com/ready-steady/ode/dopri"
+	"github.com/turing-complete/temperature/circuit"
 )
 
 func TestNew(t *testing.T) {
@@ -22,6 +23,42 @@ func TestNew(t *testing.T) {
 
 	assert.Equal(temperature.system.A, fixtureA, t)
 	assert.Equal(temperature.system.B, fixtureB, t)
+
+	assert.Equal(len(temperature.Sources), nc, t)
+	assert.Equal(temperature.Sources[0].Name, "core0", t)
+	assert.Equal(temperature.Sources[0].Width, 0.002, t)
+	assert.Equal(temperature.Targets, temperature.Sources, t)
+}
+
+func TestNewBlocks(t *testing.T) {
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+
+	blocks, _ := circuit.LoadFloorplan(config.Floorplan)
+	config.Floorplan, config.Configuration = "", ""
+	config.Blocks = blocks
+	config.Specs = circuit.DefaultSpecs()
+
+	temperature, err := New(config, nil)
+	assert.Equal(err, nil, t)
+
+	assert.Equal(temperature.system.A, fixtureA, t)
+	assert.Equal(temperature.system.B, fixtureB, t)
+	assert.Equal(temperature.Sources, blocks, t)
+}
+
+func TestNewInvalid(t *testing.T) {
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+
+	config.Ambience = -1
+	_, err := New(config, nil)
+	assert.Equal(err != nil, true, t)
+
+	config.Ambience = 318.15
+	config.Configuration = findFixture("missing.config")
+	_, err = New(config, nil)
+	assert.Equal(err != nil, true, t)
 }
 
 func load(nc uint) *Temperature {
@@ -35,7 +72,9 @@ func load(nc uint) *Temperature {
 		RelError: 1e-3,
 	})
 
-	return New(config, integrator)
+	temperature, _ := New(config, integrator)
+
+	return temperature
 }
 
 func findFixture(name string) string {
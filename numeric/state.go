@@ -0,0 +1,22 @@
+package numeric
+
+// State is a state of a thermal system at a particular time moment.
+//
+// A state is either calculated from the temperature of the thermal nodes (see
+// State in Temperature) or returned by a computation as the state of the
+// system at the last time moment. In both cases, it can be passed to a
+// subsequent computation as the initial state, which resumes the computation.
+// A nil state corresponds to the ambient temperature. The integrator never
+// modifies states passed to it.
+type State struct {
+	s []float64
+}
+
+// vector returns the state vector of the state, which is nil if the state is
+// nil. The vector should not be modified.
+func (self *State) vector() []float64 {
+	if self == nil {
+		return nil
+	}
+	return self.s
+}
@@ -0,0 +1,135 @@
+package numeric
+
+import (
+	"errors"
+	"math"
+
+	"github.com/turing-complete/temperature/circuit"
+	"github.com/turing-complete/temperature/internal/check"
+)
+
+// SteadyState calculates the steady-state temperature profile corresponding to
+// a power profile.
+//
+// The power profile is specified by a matrix P containing power samples. Each
+// sample is treated independently as a constant power dissipation, and the
+// corresponding linear system is solved directly using the factorization of
+// the conductance matrix computed by New. The input is not validated; see
+// SteadyStateChecked.
+func (self *Temperature) SteadyState(P []float64) []float64 {
+	nc, nn, no := self.nc, self.nn, self.no
+	ns := uint(len(P)) / nc
+
+	G, in, out := self.factor, self.in, self.out
+
+	S := make([]float64, nn)
+	Q, Qamb := make([]float64, no*ns), self.system.Qamb
+	for i := uint(0); i < ns; i++ {
+		for j := uint(0); j < nn; j++ {
+			S[j] = 0
+		}
+		for j := uint(0); j < nc; j++ {
+			for _, link := range in[j] {
+				S[link.Node] += link.Weight * P[i*nc+j]
+			}
+		}
+		substitute(G, S, nn)
+		for j := uint(0); j < no; j++ {
+			Q[i*no+j] = circuit.Measure(out[j], S) + Qamb
+		}
+	}
+
+	return Q
+}
+
+// SteadyStateChecked is the same as SteadyState except that the input is
+// validated, and an error is returned if it is invalid.
+func (self *Temperature) SteadyStateChecked(P []float64) ([]float64, error) {
+	if _, err := check.Power(P, self.nc); err != nil {
+		return nil, err
+	}
+	return self.SteadyState(P), nil
+}
+
+// resistance computes the matrix whose column j is the steady-state temperature
+// of the sources given by probe relative to the ambience when source j given
+// by in dissipates unit power. The Cholesky factor of the conductance matrix
+// is given by G (see conductance).
+func resistance(G []float64, in, probe [][]circuit.Link, nn uint) []float64 {
+	nc := uint(len(in))
+
+	S := make([]float64, nn)
+	R := make([]float64, nc*nc)
+	for j := uint(0); j < nc; j++ {
+		for i := uint(0); i < nn; i++ {
+			S[i] = 0
+		}
+		for _, link := range in[j] {
+			S[link.Node] += link.Weight
+		}
+		substitute(G, S, nn)
+		for k := uint(0); k < nc; k++ {
+			R[j*nc+k] = circuit.Measure(probe[k], S)
+		}
+	}
+
+	return R
+}
+
+// conductance returns the Cholesky factor of the conductance matrix of a
+// system (see factorize).
+func conductance(system *system, nn uint) ([]float64, error) {
+	A, B := system.A, system.B
+
+	G := make([]float64, nn*nn)
+	for i := uint(0); i < nn; i++ {
+		for j := uint(0); j < nn; j++ {
+			G[j*nn+i] = -A[j*nn+i] / B[i]
+		}
+	}
+	if err := factorize(G, nn); err != nil {
+		return nil, err
+	}
+
+	return G, nil
+}
+
+// factorize overwrites the lower triangle of a symmetric positive-definite
+// matrix A with its Cholesky factor L such that A = L * L**T.
+func factorize(A []float64, n uint) error {
+	for j := uint(0); j < n; j++ {
+		sum := A[j*n+j]
+		for k := uint(0); k < j; k++ {
+			sum -= A[k*n+j] * A[k*n+j]
+		}
+		if sum <= 0 || math.IsNaN(sum) {
+			return errors.New("the conductance matrix should be positive definite")
+		}
+		A[j*n+j] = math.Sqrt(sum)
+		for i := j + 1; i < n; i++ {
+			sum := A[j*n+i]
+			for k := uint(0); k < j; k++ {
+				sum -= A[k*n+i] * A[k*n+j]
+			}
+			A[j*n+i] = sum / A[j*n+j]
+		}
+	}
+	return nil
+}
+
+// substitute overwrites a vector b with the solution to L * L**T * x = b where
+// L is a Cholesky factor computed by factorize.
+func substitute(L, b []float64, n uint) {
+	for i := uint(0); i < n; i++ {
+		for k := uint(0); k < i; k++ {
+			b[i] -= L[k*n+i] * b[k]
+		}
+		b[i] /= L[i*n+i]
+	}
+	for i := n; i > 0; i-- {
+		for k := i; k < n; k++ {
+			b[i-1] -= L[(i-1)*n+k] * b[k]
+		}
+		b[i-1] /= L[(i-1)*n+i-1]
+	}
+}
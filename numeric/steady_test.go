@@ -0,0 +1,98 @@
+package numeric
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ready-steady/assert"
+	"github.com/ready-steady/fixture"
+	"github.com/turing-complete/temperature/circuit"
+)
+
+func TestSteadyState(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature := load(nc)
+	Q, err := temperature.SteadyStateChecked([]float64{10, 20, 15, 5})
+
+	assert.Equal(err, nil, t)
+	assert.Close(Q, fixtureQSteady, 1e-10, t)
+}
+
+func TestSteadyStateInvalid(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	temperature := load(nc)
+
+	_, err := temperature.SteadyStateChecked([]float64{10, 20, 15})
+	assert.Equal(err != nil, true, t)
+
+	_, err = temperature.SteadyStateChecked([]float64{10, math.NaN()})
+	assert.Equal(err != nil, true, t)
+
+	_, err = temperature.State(make([]float64, 1))
+	assert.Equal(err != nil, true, t)
+}
+
+func TestSteadyStateStack(t *testing.T) {
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Floorplan, config.Stack = "", findFixture("002.lcf")
+
+	temperature, err := New(config, nil)
+	assert.Equal(err, nil, t)
+
+	Q := temperature.SteadyState([]float64{10, 20, 15, 5})
+	assert.Close(Q, fixtureQSteady, 1e-10, t)
+}
+
+func TestSteadyStateSecondary(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	blocks, _ := circuit.LoadFloorplan(config.Floorplan)
+	config.Floorplan, config.Configuration = "", ""
+	config.Blocks = blocks
+	config.Specs = circuit.DefaultSpecs()
+	config.Specs.Secondary = true
+
+	P := []float64{10, 20, 15, 5}
+
+	temperature, err := New(config, nil)
+	assert.Equal(err, nil, t)
+	assert.Equal(temperature.nn, uint(9*nc+12+16), t)
+
+	Q1 := load(nc).SteadyState(P)
+	Q2 := temperature.SteadyState(P)
+
+	sum1, sum2 := 0.0, 0.0
+	for i := range Q2 {
+		sum1 += P[i] * (Q1[i] - config.Ambience)
+		sum2 += P[i] * (Q2[i] - config.Ambience)
+	}
+	assert.Equal(sum2 < sum1, true, t)
+}
+
+func TestSteadyStateSubset(t *testing.T) {
+	const (
+		nc = 2
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.Sources = []string{"core1"}
+	config.Targets = []string{"core0"}
+
+	temperature, _ := New(config, nil)
+	Q1 := temperature.SteadyState([]float64{20, 5})
+	Q := load(nc).SteadyState([]float64{0, 20, 0, 5})
+
+	assert.Close(Q1, []float64{Q[0], Q[2]}, 1e-12, t)
+}
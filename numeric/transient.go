@@ -2,6 +2,9 @@ package numeric
 
 import (
 	"github.com/ready-steady/linear/matrix"
+	"github.com/turing-complete/temperature/circuit"
+	"github.com/turing-complete/temperature/internal/check"
+	"github.com/turing-complete/temperature/internal/runaway"
 )
 
 // Compute calculates the temperature profile corresponding to a power profile.
@@ -9,38 +12,159 @@ import (
 // The power profile is specified by a function func(time float64, power
 // []float64) evaluating the power dissipation at an arbitrary time moment. The
 // time moments for which the temperature profile is computed are specified by
-// the time array; see the corresponding ODE solver for further details.
+// the time array; see the corresponding ODE solver for further details. The
+// initial state of the system is specified by S0 (see State); if S0 is nil,
+// the system starts at the ambient temperature. The state of the system at the
+// last time moment is returned along with the temperature profile and the time
+// moments so that the computation can be resumed by passing the state to a
+// subsequent call. Note that the step size of an adaptive ODE solver is not
+// preserved across calls.
 //
 // http://godoc.org/github.com/ready-steady/ode#Integrator
-func (self *Temperature) Compute(power func(float64, []float64),
-	time []float64) ([]float64, []float64, error) {
+func (self *Temperature) Compute(power func(float64, []float64), time []float64,
+	S0 *State) ([]float64, []float64, *State, error) {
 
-	nc, nn := self.nc, self.nn
+	return self.compute(power, time, S0, nil)
+}
+
+// ComputeWithStatic calculates the temperature profile corresponding to a
+// dynamic power profile taking into account the static power.
+//
+// The arguments are the same as those of Compute. In addition, the static
+// power is specified by a function leak(Q, P) that receives the temperature Q
+// of the blocks dissipating power (see Sources) and adds the corresponding
+// static power to the power P of these blocks. The function is evaluated
+// within the right-hand side of the system of differential equations, so the
+// coupling between temperature and static power is resolved by the ODE solver
+// itself. The solver evaluates it at trial states, and the detection of
+// thermal runaway evaluates it again at perturbed temperature; hence, the
+// function should be free of side effects and accept any temperature.
+//
+// Thermal runaway is checked only after the ODE solver has finished, at the
+// resulting time moments (see Ceiling in Config), and a RunawayError is
+// returned if it is detected. If the temperature diverges to the point that
+// the solver itself fails, the error of the solver is returned instead.
+func (self *Temperature) ComputeWithStatic(power func(float64, []float64),
+	time []float64, S0 *State, leak func([]float64, []float64)) ([]float64, []float64,
+	*State, error) {
+
+	return self.compute(power, time, S0, leak)
+}
+
+func (self *Temperature) compute(power func(float64, []float64), time []float64,
+	S0 *State, leak func([]float64, []float64)) ([]float64, []float64, *State, error) {
+
+	nc, nn, no := self.nc, self.nn, self.no
+	if err := check.State(S0.vector(), nn); err != nil {
+		return nil, nil, nil, err
+	}
 
 	A, B := self.system.A, self.system.B
+	in, out, probe := self.in, self.out, self.probe
+	Qamb := self.system.Qamb
 	P := make([]float64, nc)
+	L := make([]float64, nc)
 
 	dSdt := func(self float64, S, dSdt []float64) {
 		matrix.Multiply(A, S, dSdt, nn, nn, 1)
 		power(self, P)
+		if leak != nil {
+			for i := uint(0); i < nc; i++ {
+				L[i] = circuit.Measure(probe[i], S) + Qamb
+			}
+			leak(L, P)
+		}
 		for i := uint(0); i < nc; i++ {
-			dSdt[i] += B[i] * P[i]
+			for _, link := range in[i] {
+				dSdt[link.Node] += B[link.Node] * link.Weight * P[i]
+			}
 		}
 	}
 
-	S, time, err := self.integrator.Compute(dSdt, make([]float64, nn), time)
+	S := make([]float64, nn)
+	copy(S, S0.vector())
+
+	S, time, err := self.integrator.Compute(dSdt, S, time)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	ns := uint(len(time))
 
-	Q, Qamb := make([]float64, ns*nc), self.system.Qamb
-	for i := uint(0); i < nc; i++ {
+	S1 := S0
+	if ns > 0 {
+		S1 = &State{s: append([]float64(nil), S[(ns-1)*nn:]...)}
+	}
+
+	if leak != nil {
+		if err := self.detect(power, time, S, leak); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	Q := make([]float64, ns*no)
+	for i := uint(0); i < no; i++ {
 		for j := uint(0); j < ns; j++ {
-			Q[j*nc+i] = S[j*nn+i] + Qamb
+			Q[j*no+i] = circuit.Measure(out[i], S[j*nn:]) + Qamb
+		}
+	}
+
+	return Q, time, S1, nil
+}
+
+// detect checks for thermal runaway at the time moments of a solution S
+// computed by ComputeWithStatic.
+func (self *Temperature) detect(power func(float64, []float64), time, S []float64,
+	leak func([]float64, []float64)) error {
+
+	nc, nn, probe, Qamb := self.nc, self.nn, self.probe, self.system.Qamb
+
+	detector := runaway.New(self.resistance, Qamb, self.qmax, self.Sources)
+
+	P := make([]float64, nc)
+	L := make([]float64, nc)
+	for j := range time {
+		for i := uint(0); i < nc; i++ {
+			L[i] = circuit.Measure(probe[i], S[uint(j)*nn:]) + Qamb
+		}
+		power(time[j], P)
+		leak(L, P)
+		if err := detector.Check(uint(j), L, P, leak); err != nil {
+			return err
 		}
 	}
 
-	return Q, time, nil
+	return nil
+}
+
+// State calculates the state of the system corresponding to the temperature of
+// the thermal nodes.
+//
+// The temperature is specified by a vector Q containing one value per thermal
+// node. The result can be used as the initial state in Compute.
+func (self *Temperature) State(Q []float64) (*State, error) {
+	nn, Qamb := self.nn, self.system.Qamb
+	if err := check.Temperature(Q, nn); err != nil {
+		return nil, err
+	}
+
+	S := make([]float64, nn)
+	for i := uint(0); i < nn; i++ {
+		S[i] = Q[i] - Qamb
+	}
+
+	return &State{s: S}, nil
+}
+
+// Initial returns the state of the system corresponding to the initial
+// temperature of the thermal nodes given by the configuration of the thermal
+// RC circuit (see InitialTemperature and InitialFile in circuit.Specs). The
+// result can be used as the initial state in Compute. An error reading the
+// file with the initial temperature is reported here.
+func (self *Temperature) Initial() (*State, error) {
+	Q, err := self.initial()
+	if err != nil {
+		return nil, err
+	}
+	return self.State(Q)
 }
@@ -1,9 +1,12 @@
 package numeric
 
 import (
+	"math"
 	"testing"
 
 	"github.com/ready-steady/assert"
+	"github.com/ready-steady/fixture"
+	"github.com/ready-steady/ode/dopri"
 )
 
 func TestCompute002Fixed(t *testing.T) {
@@ -17,7 +20,7 @@ func TestCompute002Fixed(t *testing.T) {
 	power := smooth(fixtureP, nc, ns, Δt)
 	time := sequence(ns, Δt)
 
-	Q, _, _ := temperature.Compute(power, time)
+	Q, _, _, _ := temperature.Compute(power, time, nil)
 
 	assert.Close(Q, fixtureQ, 2e-10, t)
 }
@@ -31,12 +34,182 @@ func TestCompute002Adaptive(t *testing.T) {
 
 	temperature := load(nc)
 	power := smooth(fixtureP, nc, ns, Δt)
-	Q, time, _ := temperature.Compute(power, []float64{0, ns * Δt})
+	Q, time, _, _ := temperature.Compute(power, []float64{0, ns * Δt}, nil)
 
 	assert.Close(Q, fixtureQTime, 1e-10, t)
 	assert.Close(time, fixtureTime, 1e-14, t)
 }
 
+func TestCompute002Initial(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		ns = 440
+		Δt = 1e-3
+		q0 = 333.15
+	)
+
+	temperature := load(nc)
+	power := smooth(make([]float64, nc*ns), nc, ns, Δt)
+	time := sequence(ns, Δt)
+
+	Q0 := make([]float64, nn)
+	for i := range Q0 {
+		Q0[i] = q0
+	}
+
+	S0, _ := temperature.State(Q0)
+	S, err := temperature.Initial()
+	assert.Equal(err, nil, t)
+	assert.Equal(S, S0, t) // init_temp in hotspot.config
+
+	Q, _, _, _ := temperature.Compute(power, time, S0)
+
+	assert.Equal(Q[:nc], Q0[:nc], t)
+	for i := range Q {
+		if Q[i] > q0+1e-3 || Q[i] < temperature.system.Qamb {
+			t.Fatalf("unexpected temperature %v", Q[i])
+		}
+	}
+}
+
+func TestCompute002Resume(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		ns = 440
+		Δt = 1e-3
+	)
+
+	temperature := load(nc)
+	power := smooth(fixtureP, nc, ns, Δt)
+	time := sequence(ns, Δt)
+
+	Q, _, S, _ := temperature.Compute(power, time, nil)
+	assert.Equal(len(S.s), nn, t)
+
+	Qamb := temperature.system.Qamb
+	for i := uint(0); i < nc; i++ {
+		assert.Equal(S.s[i]+Qamb, Q[(ns-1)*nc+i], t)
+	}
+
+	S0 := append([]float64(nil), S.s...)
+	_, _, S1, _ := temperature.Compute(power, time, S)
+	assert.Equal(S.s, S0, t)
+	assert.Equal(S1 != S, true, t)
+}
+
+func TestCompute002AllNodes(t *testing.T) {
+	const (
+		nc = 2
+		nn = 4*nc + 12
+		ns = 440
+		Δt = 1e-3
+	)
+
+	config := &Config{}
+	fixture.Load(findFixture("002.json"), config)
+	config.AllNodes = true
+
+	temperature, _ := New(config, load(nc).integrator)
+	power := smooth(fixtureP, nc, ns, Δt)
+	time := sequence(ns, Δt)
+
+	Q, _, _, _ := temperature.Compute(power, time, nil)
+	assert.Equal(len(Q), nn*ns, t)
+
+	_, _, S, _ := load(nc).Compute(power, time, nil)
+	S1, _ := temperature.State(Q[(ns-1)*nn:])
+	assert.Close(S1.s, S.s, 1e-12, t)
+}
+
+func TestCompute002WithStatic(t *testing.T) {
+	const (
+		nc = 2
+		ns = 440
+		Δt = 1e-3
+	)
+
+	temperature := load(nc)
+	power := smooth(fixtureP, nc, ns, Δt)
+	time := sequence(ns, Δt)
+
+	leak := func(_, P []float64) {
+		for i := range P {
+			P[i] += 1
+		}
+	}
+	Q, _, _, _ := temperature.ComputeWithStatic(power, time, nil, leak)
+
+	total := func(time float64, P []float64) {
+		power(time, P)
+		leak(nil, P)
+	}
+	Q1, _, _, _ := temperature.Compute(total, time, nil)
+
+	assert.Equal(Q, Q1, t)
+
+	leak = func(Q, P []float64) {
+		for i := range P {
+			P[i] += 0.05 * (Q[i] - 300)
+		}
+	}
+	Q, _, _, _ = temperature.ComputeWithStatic(power, time, nil, leak)
+
+	Q1, _, _, _ = temperature.Compute(power, time, nil)
+	for i := range Q {
+		if Q[i] < Q1[i] {
+			t.Fatalf("the static power should not decrease the temperature")
+		}
+	}
+}
+
+func TestCompute002WithStaticRunaway(t *testing.T) {
+	const (
+		nc = 2
+		ns = 40
+		Δt = 1e-2
+	)
+
+	temperature := load(nc)
+	temperature.integrator, _ = dopri.New(&dopri.Config{
+		MaxStep:  1e-4,
+		TryStep:  1e-5,
+		AbsError: 1e-3,
+		RelError: 1e-3,
+	})
+
+	power := func(_ float64, P []float64) {
+		for i := range P {
+			P[i] = 5
+		}
+	}
+	time := sequence(ns, Δt)
+
+	leak := func(rate float64) func([]float64, []float64) {
+		return func(Q, P []float64) {
+			for i := range P {
+				P[i] += 2 * math.Exp(rate*(Q[i]-temperature.system.Qamb))
+			}
+		}
+	}
+
+	_, _, _, err := temperature.ComputeWithStatic(power, time, nil, leak(0.03))
+	assert.Equal(err, nil, t)
+
+	_, _, _, err = temperature.ComputeWithStatic(power, time, nil, leak(0.05))
+	runaway, ok := err.(*RunawayError)
+	assert.Equal(ok, true, t)
+	assert.Equal(runaway.Sample > 0, true, t)
+	assert.Equal(runaway.Temperature < 400, true, t)
+
+	temperature.qmax = temperature.system.Qamb + 10
+	_, _, _, err = temperature.ComputeWithStatic(power, time, nil, leak(0.03))
+	runaway, ok = err.(*RunawayError)
+	assert.Equal(ok, true, t)
+	assert.Equal(runaway.Temperature > temperature.qmax, true, t)
+}
+
 func BenchmarkCompute002Adaptive(b *testing.B) { benchmarkComputeAdaptive(2, 1000, 1e-3, b) }
 func BenchmarkCompute032Adaptive(b *testing.B) { benchmarkComputeAdaptive(32, 1000, 1e-3, b) }
 
@@ -48,7 +221,7 @@ func benchmarkComputeAdaptive(nc, ns uint, Δt float64, b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		temperature.Compute(power, time)
+		temperature.Compute(power, time, nil)
 	}
 }
 
@@ -63,7 +236,7 @@ func benchmarkComputeFixed(nc, ns uint, Δt float64, b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		temperature.Compute(power, time)
+		temperature.Compute(power, time, nil)
 	}
 }
 